@@ -25,6 +25,8 @@ type MetadataInstallation struct {
 	LaunchPath string `json:"launchPath"`
 	Name       string `json:"name"`
 	Profile    string `json:"profile"`
+	Label      string `json:"label,omitempty"`
+	Hidden     bool   `json:"hidden,omitempty"`
 }
 
 type Metadata struct {
@@ -55,7 +57,7 @@ func addFactoryGameLog(writer *zip.Writer) error {
 }
 
 func addMetadata(writer *zip.Writer) error {
-	installs := ficsitcli.FicsitCLI.GetInstallations()
+	installs := append(ficsitcli.FicsitCLI.GetInstallations(), ficsitcli.FicsitCLI.GetHiddenInstallations()...)
 	selectedInstallInstance := ficsitcli.FicsitCLI.GetSelectedInstall()
 	metadataInstalls := make([]*MetadataInstallation, 0)
 	var selectedMetadataInstall *MetadataInstallation
@@ -70,6 +72,9 @@ func addMetadata(writer *zip.Writer) error {
 			Name:         fmt.Sprintf("Satisfactory %s (%s)", metadata.Info.Branch, metadata.Info.Branch),
 			Profile:      ficsitcli.FicsitCLI.GetInstallation(install).Profile,
 		}
+		label := ficsitcli.FicsitCLI.GetInstallationLabel(install)
+		i.Label = label.Label
+		i.Hidden = label.Hidden
 		i.Path = utils.RedactPath(i.Path)
 		i.LaunchPath = strings.Join(i.Installation.LaunchPath, " ")
 
@@ -0,0 +1,234 @@
+// Package credentials stores the secrets used to connect to remote installations.
+//
+// The store is encrypted with a key kept next to it in the SMM local data directory.
+// This keeps the secrets out of installations.json, debug info zips, and backups or sync of the profiles directory,
+// which are the files users share. It does not protect them from anyone who can read the local data directory,
+// such as other programs running as the same user, since the key is readable by them too.
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Credential holds the secrets needed to connect to a remote installation.
+// Installation paths only contain a reference to it, see ID.
+type Credential struct {
+	Password string `json:"password,omitempty"`
+
+	// SFTP only
+	PrivateKeyPath string `json:"privateKeyPath,omitempty"`
+	Passphrase     string `json:"passphrase,omitempty"`
+	UseAgent       bool   `json:"useAgent,omitempty"`
+}
+
+func (c Credential) IsEmpty() bool {
+	return c == Credential{}
+}
+
+type store struct {
+	Credentials map[string]Credential `json:"credentials"`
+}
+
+var (
+	credentialsFileName = "credentials.dat"
+	keyFileName         = "credentials.key"
+
+	storeLock sync.RWMutex
+	loaded    = store{Credentials: make(map[string]Credential)}
+)
+
+// ID returns the identifier of the credential used by a remote path with the given scheme, user and host.
+// Paths that point to the same account on the same server share the credential.
+func ID(scheme, username, host string) string {
+	return scheme + "://" + username + "@" + host
+}
+
+func Get(id string) (Credential, bool) {
+	storeLock.RLock()
+	defer storeLock.RUnlock()
+	c, ok := loaded.Credentials[id]
+	return c, ok
+}
+
+func Set(id string, credential Credential) error {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	loaded.Credentials[id] = credential
+	return saveLocked()
+}
+
+func Delete(id string) error {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+	if _, ok := loaded.Credentials[id]; !ok {
+		return nil
+	}
+	delete(loaded.Credentials, id)
+	return saveLocked()
+}
+
+// ErrStoreReset is returned by LoadCredentials when the stored credentials could not be read,
+// so they were set aside and the user has to enter them again
+var ErrStoreReset = errors.New("stored credentials could not be read and were reset")
+
+// LoadCredentials loads the credential store. If it can't be read, for example because the key file is corrupt,
+// it is moved aside and an empty store is used instead, returning an error wrapping ErrStoreReset.
+func LoadCredentials() error {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+
+	s, err := readStore()
+	if err != nil {
+		if resetErr := resetStoreLocked(); resetErr != nil {
+			return fmt.Errorf("%w: %w, and failed to reset them: %w", ErrStoreReset, err, resetErr)
+		}
+		return fmt.Errorf("%w: %w", ErrStoreReset, err)
+	}
+	loaded = s
+	return nil
+}
+
+func readStore() (store, error) {
+	s := store{Credentials: make(map[string]Credential)}
+
+	data, err := os.ReadFile(filepath.Join(viper.GetString("smm-local-dir"), credentialsFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return s, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	key, err := getKey()
+	if err != nil {
+		return s, err
+	}
+
+	plain, err := decrypt(key, data)
+	if err != nil {
+		return s, fmt.Errorf("failed to decrypt credentials: %w", err)
+	}
+
+	if err := json.Unmarshal(plain, &s); err != nil {
+		return s, fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+	if s.Credentials == nil {
+		s.Credentials = make(map[string]Credential)
+	}
+	return s, nil
+}
+
+// resetStoreLocked moves the unreadable store and its key aside, so they can still be recovered manually,
+// and starts with an empty store, with a new key generated on the next save
+func resetStoreLocked() error {
+	loaded = store{Credentials: make(map[string]Credential)}
+	for _, name := range []string{credentialsFileName, keyFileName} {
+		path := filepath.Join(viper.GetString("smm-local-dir"), name)
+		err := os.Rename(path, path+".invalid")
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to move %s aside: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func saveLocked() error {
+	plain, err := json.Marshal(loaded)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	key, err := getKey()
+	if err != nil {
+		return err
+	}
+
+	data, err := encrypt(key, plain)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	err = os.WriteFile(filepath.Join(viper.GetString("smm-local-dir"), credentialsFileName), data, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return nil
+}
+
+// getKey reads the local encryption key, generating it on first use.
+// The key never leaves this machine, so copying installations.json or
+// a debug info zip does not expose any server passwords.
+func getKey() ([]byte, error) {
+	keyPath := filepath.Join(viper.GetString("smm-local-dir"), keyFileName)
+
+	key, err := os.ReadFile(keyPath)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("invalid credentials key length %d", len(key))
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read credentials key: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate credentials key: %w", err)
+	}
+	if err := os.WriteFile(keyPath, key, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write credentials key: %w", err)
+	}
+	return key, nil
+}
+
+func encrypt(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("credentials file is too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open credentials: %w", err)
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
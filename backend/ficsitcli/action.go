@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"sync"
 	"time"
 
 	"github.com/puzpuzpuz/xsync/v3"
@@ -12,6 +14,7 @@ import (
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
 )
 
 type taskUpdate struct {
@@ -19,12 +22,17 @@ type taskUpdate struct {
 	progress ProgressTask
 }
 
-func (f *ficsitCLI) action(action Action, item ProgressItem, run func(*slog.Logger, chan<- taskUpdate) error) error {
-	if !f.actionMutex.TryLock() {
-		return fmt.Errorf("another operation in progress")
-	}
-	defer f.actionMutex.Unlock()
+func (f *ficsitCLI) installLock(path string) *sync.Mutex {
+	lock, _ := f.installLocks.LoadOrCompute(path, func() *sync.Mutex {
+		return &sync.Mutex{}
+	})
+	return lock
+}
 
+// action runs an operation on an installation. Only one operation can run on an installation at a time,
+// but operations on different installations can run in parallel, each with its own progress.
+// While the game of the installation is running, the operation is rejected, or queued until the game exits, returning ErrActionQueued.
+func (f *ficsitCLI) action(installation *cli.Installation, action Action, item ProgressItem, run func(*slog.Logger, chan<- taskUpdate) error) error {
 	var logAttrs []any
 	logAttrs = append(logAttrs, slog.String("type", string(action)))
 	if item != noItem {
@@ -33,16 +41,50 @@ func (f *ficsitCLI) action(action Action, item ProgressItem, run func(*slog.Logg
 			logAttrs = append(logAttrs, slog.String("version", item.Version))
 		}
 	}
-	l := slog.With(slog.Group("action", logAttrs...))
+	l := slog.With(slog.Group("action", logAttrs...), slog.String("install", installation.Path))
+
+	if f.IsInstallationBlocked(installation.Path) {
+		if !settings.Settings.QueueModChangesWhileRunning {
+			l.Warn("game is running, rejecting action")
+			return ErrGameRunning
+		}
+		f.queueAction(l, installation, action, item, run)
+		return ErrActionQueued
+	}
+
+	lock := f.installLock(installation.Path)
+	if !lock.TryLock() {
+		return fmt.Errorf("another operation in progress")
+	}
+	defer lock.Unlock()
+
+	return f.runAction(l, installation, action, item, run)
+}
 
+// runAction runs an operation while holding the lock of its installation, and sends its progress
+func (f *ficsitCLI) runAction(l *slog.Logger, installation *cli.Installation, action Action, item ProgressItem, run func(*slog.Logger, chan<- taskUpdate) error) error {
 	done := make(chan bool)
 	defer close(done)
 
-	progress := newProgress(action, item)
+	// The global progress only shows the operations on the installation the user is looking at,
+	// which is checked on every update, since the user can select another installation while this one is busy
+	wasSelected := false
+	emitProgress := func(progress *Progress) {
+		wailsRuntime.EventsEmit(common.AppContext, "installProgress", installation.Path, progress)
+		isSelected := action == ActionSelectInstall || installation.Path == f.getSelectedInstallationPath()
+		if isSelected {
+			wailsRuntime.EventsEmit(common.AppContext, "progress", progress)
+		} else if wasSelected {
+			wailsRuntime.EventsEmit(common.AppContext, "progress", nil)
+		}
+		wasSelected = isSelected
+	}
+
+	progress := newProgress(installation.Path, action, item)
 	tasks := xsync.NewMapOf[string, ProgressTask]()
 	go func() {
-		wailsRuntime.EventsEmit(common.AppContext, "progress", progress)
-		defer wailsRuntime.EventsEmit(common.AppContext, "progress", nil)
+		emitProgress(progress)
+		defer emitProgress(nil)
 
 		progressTicker := time.NewTicker(100 * time.Millisecond)
 		defer progressTicker.Stop()
@@ -56,7 +98,7 @@ func (f *ficsitCLI) action(action Action, item ProgressItem, run func(*slog.Logg
 					progress.Tasks[key] = value
 					return true
 				})
-				wailsRuntime.EventsEmit(common.AppContext, "progress", progress)
+				emitProgress(progress)
 			}
 		}
 	}()
@@ -113,7 +155,7 @@ func (f *ficsitCLI) validateInstall(installation *cli.Installation, taskChannel
 		}
 	}()
 
-	installErr := installation.Install(f.ficsitCli, installChannel)
+	installErr := installation.Install(f.installContext(installation), installChannel)
 	if installErr != nil {
 		var solvingError resolver.DependencyResolverError
 		if errors.As(installErr, &solvingError) {
@@ -123,3 +165,25 @@ func (f *ficsitCLI) validateInstall(installation *cli.Installation, taskChannel
 	}
 	return nil
 }
+
+// contextSnapshot returns a ficsit-cli context with a copy of the profiles,
+// so long operations can read them without holding profilesLock
+func (f *ficsitCLI) contextSnapshot() *cli.GlobalContext {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+
+	profiles := &cli.Profiles{
+		Profiles:        make(map[string]*cli.Profile, len(f.ficsitCli.Profiles.Profiles)),
+		SelectedProfile: f.ficsitCli.Profiles.SelectedProfile,
+		Version:         f.ficsitCli.Profiles.Version,
+	}
+	for name, profile := range f.ficsitCli.Profiles.Profiles {
+		profileCopy := *profile
+		profileCopy.Mods = maps.Clone(profile.Mods)
+		profiles.Profiles[name] = &profileCopy
+	}
+
+	snapshot := *f.ficsitCli
+	snapshot.Profiles = profiles
+	return &snapshot
+}
@@ -0,0 +1,122 @@
+package ficsitcli
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+)
+
+type queuedAction struct {
+	logger       *slog.Logger
+	installation *cli.Installation
+	progress     *Progress
+	run          func(*slog.Logger, chan<- taskUpdate) error
+}
+
+// QueuedActionError is sent in the queuedActionFailed event when a queued operation fails after the game exits
+type QueuedActionError struct {
+	Progress *Progress `json:"progress"`
+	Message  string    `json:"message"`
+}
+
+// queueAction makes an operation wait until the game of its installation exits, without holding the installation lock,
+// so the frontend is not left waiting, and the operation can be cancelled
+func (f *ficsitCLI) queueAction(l *slog.Logger, installation *cli.Installation, action Action, item ProgressItem, run func(*slog.Logger, chan<- taskUpdate) error) {
+	progress := newProgress(installation.Path, action, item)
+	progress.Queued = true
+
+	f.queuedActionsLock.Lock()
+	queue, waiting := f.queuedActions[installation.Path]
+	f.queuedActions[installation.Path] = append(queue, &queuedAction{
+		logger:       l,
+		installation: installation,
+		progress:     progress,
+		run:          run,
+	})
+	f.queuedActionsLock.Unlock()
+
+	l.Info("game is running, queued action until it exits")
+	f.emitQueuedActions()
+
+	if !waiting {
+		go f.runQueuedActions(installation.Path)
+	}
+}
+
+// runQueuedActions waits for the game of the installation to exit, then runs its queued operations in order.
+// It stops early if the operations are cancelled.
+func (f *ficsitCLI) runQueuedActions(path string) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		f.queuedActionsLock.Lock()
+		_, waiting := f.queuedActions[path]
+		f.queuedActionsLock.Unlock()
+		if !waiting {
+			return
+		}
+		if !f.IsInstallationBlocked(path) {
+			break
+		}
+	}
+
+	f.queuedActionsLock.Lock()
+	queue := f.queuedActions[path]
+	delete(f.queuedActions, path)
+	f.queuedActionsLock.Unlock()
+	f.emitQueuedActions()
+
+	lock := f.installLock(path)
+	for _, queued := range queue {
+		queued.logger.Info("game exited, running queued action")
+		lock.Lock()
+		err := f.runAction(queued.logger, queued.installation, queued.progress.Action, queued.progress.Item, queued.run)
+		lock.Unlock()
+		if err != nil {
+			wailsRuntime.EventsEmit(common.AppContext, "queuedActionFailed", QueuedActionError{
+				Progress: queued.progress,
+				Message:  err.Error(),
+			})
+		}
+	}
+}
+
+// GetQueuedActions returns the operations that wait for the game of their installation to exit
+func (f *ficsitCLI) GetQueuedActions() []*Progress {
+	f.queuedActionsLock.Lock()
+	defer f.queuedActionsLock.Unlock()
+	paths := make([]string, 0, len(f.queuedActions))
+	for path := range f.queuedActions {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	actions := make([]*Progress, 0)
+	for _, path := range paths {
+		for _, queued := range f.queuedActions[path] {
+			actions = append(actions, queued.progress)
+		}
+	}
+	return actions
+}
+
+// CancelQueuedActions drops the operations on the installation that wait for its game to exit
+func (f *ficsitCLI) CancelQueuedActions(path string) {
+	f.queuedActionsLock.Lock()
+	cancelled := len(f.queuedActions[path])
+	delete(f.queuedActions, path)
+	f.queuedActionsLock.Unlock()
+
+	slog.Info("cancelled queued actions", slog.String("path", path), slog.Int("count", cancelled))
+	f.emitQueuedActions()
+}
+
+func (f *ficsitCLI) emitQueuedActions() {
+	if common.AppContext != nil {
+		wailsRuntime.EventsEmit(common.AppContext, "queuedActions", f.GetQueuedActions())
+	}
+}
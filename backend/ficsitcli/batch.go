@@ -0,0 +1,149 @@
+package ficsitcli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+)
+
+type BatchResult struct {
+	Installation string `json:"installation"`
+	Success      bool   `json:"success"`
+	// Queued is set when the action was queued until the game of the installation exits, so it has not happened yet
+	Queued bool   `json:"queued,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchProgress is the combined progress of a batch action.
+// The progress of each installation is sent separately in installProgress events.
+type BatchProgress struct {
+	Action        Action        `json:"action"`
+	Item          ProgressItem  `json:"item"`
+	Installations []string      `json:"installations"`
+	Results       []BatchResult `json:"results"`
+}
+
+// runBatch runs the action on each of the installations in parallel, and reports the result for each of them.
+// A failure on one installation does not stop the others.
+func (f *ficsitCLI) runBatch(action Action, item ProgressItem, installs []string, run func(*cli.Installation) error) []BatchResult {
+	l := slog.With(slog.String("task", "batch"), slog.String("type", string(action)))
+
+	progress := &BatchProgress{
+		Action:        action,
+		Item:          item,
+		Installations: installs,
+		Results:       make([]BatchResult, 0, len(installs)),
+	}
+	var progressLock sync.Mutex
+	emitProgress := func(progress *BatchProgress) {
+		if common.AppContext != nil {
+			wailsRuntime.EventsEmit(common.AppContext, "batchProgress", progress)
+		}
+	}
+	emitProgress(progress)
+	defer emitProgress(nil)
+
+	results := make([]BatchResult, len(installs))
+	var wg sync.WaitGroup
+	for i, path := range installs {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+
+			results[i] = BatchResult{Installation: path, Success: true}
+
+			var err error
+			installation := f.GetInstallation(path)
+			if installation == nil || !f.isValidInstall(path) {
+				err = fmt.Errorf("invalid installation: %s", path)
+			} else {
+				err = run(installation)
+			}
+			if errors.Is(err, ErrActionQueued) {
+				l.Info("batch action queued", slog.String("install", path))
+				results[i].Success = false
+				results[i].Queued = true
+			} else if err != nil {
+				l.Error("batch action failed", slog.String("install", path), slog.Any("error", err))
+				results[i].Success = false
+				results[i].Error = err.Error()
+			}
+
+			progressLock.Lock()
+			progress.Results = append(progress.Results, results[i])
+			emitProgress(progress)
+			progressLock.Unlock()
+		}(i, path)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// GetInstallationsUsingProfile returns the valid installations that use the profile, to be used as the target of a batch action
+func (f *ficsitCLI) GetInstallationsUsingProfile(profile string) []string {
+	installs := make([]string, 0)
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	for _, installation := range f.ficsitCli.Installations.Installations {
+		if installation.Profile == profile && f.isValidInstall(installation.Path) {
+			installs = append(installs, installation.Path)
+		}
+	}
+	return installs
+}
+
+// InstallModOn installs the mod on each of the installations. An empty version installs the latest one.
+func (f *ficsitCLI) InstallModOn(installs []string, mod string, version string) []BatchResult {
+	return f.runBatch(ActionInstall, newItem(mod, version), installs, func(installation *cli.Installation) error {
+		return f.installMod(installation, mod, version)
+	})
+}
+
+func (f *ficsitCLI) RemoveModOn(installs []string, mod string) []BatchResult {
+	return f.runBatch(ActionUninstall, newSimpleItem(mod), installs, func(installation *cli.Installation) error {
+		return f.removeMod(installation, mod)
+	})
+}
+
+func (f *ficsitCLI) SetProfileOn(installs []string, profile string) []BatchResult {
+	return f.runBatch(ActionSelectProfile, newSimpleItem(profile), installs, func(installation *cli.Installation) error {
+		return f.setProfile(installation, profile)
+	})
+}
+
+func (f *ficsitCLI) UpdateModsOn(installs []string, mods []string) []BatchResult {
+	return f.runBatch(ActionUpdate, noItem, installs, func(installation *cli.Installation) error {
+		return f.updateMods(installation, mods)
+	})
+}
+
+// UpdateModsEverywhere updates the mods on every valid installation whose profile contains them
+func (f *ficsitCLI) UpdateModsEverywhere(mods []string) []BatchResult {
+	installs := make([]string, 0)
+	f.profilesLock.RLock()
+	for _, installation := range f.ficsitCli.Installations.Installations {
+		if !f.isValidInstall(installation.Path) {
+			continue
+		}
+		profile := f.getProfile(installation.Profile)
+		if profile == nil {
+			continue
+		}
+		for _, mod := range mods {
+			if _, ok := profile.Mods[mod]; ok {
+				installs = append(installs, installation.Path)
+				break
+			}
+		}
+	}
+	f.profilesLock.RUnlock()
+
+	return f.UpdateModsOn(installs, mods)
+}
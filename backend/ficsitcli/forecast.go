@@ -0,0 +1,119 @@
+package ficsitcli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/mircearoata/pubgrub-go/pubgrub"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+// ProfileForecast is what a profile would install on a game version, which does not have to be installed
+type ProfileForecast struct {
+	// LockFile is set if the profile can be resolved
+	LockFile *resolver.LockFile `json:"lockfile,omitempty"`
+	// Conflict is set if it can't
+	Conflict *ProfileConflict `json:"conflict,omitempty"`
+}
+
+type ProfileConflict struct {
+	// Message is the explanation of the conflict, as shown when installing fails
+	Message string `json:"message"`
+	// Mods are the references of the mods involved in the conflict, including SML
+	Mods []string `json:"mods"`
+	// GameVersion is set if the game version is one of the causes of the conflict
+	GameVersion bool `json:"gameVersion"`
+}
+
+var platformTargets = map[common.InstallType]resolver.TargetName{
+	common.InstallTypeWindowsClient: resolver.TargetNameWindows,
+	common.InstallTypeWindowsServer: resolver.TargetNameWindowsServer,
+	common.InstallTypeLinuxServer:   resolver.TargetNameLinuxServer,
+}
+
+// ResolveProfileFor resolves the profile as if it was installed on the game version and platform,
+// such as to check if it works on an upcoming experimental build, or on a Linux server
+func (f *ficsitCLI) ResolveProfileFor(profileName string, gameVersion int, platform common.InstallType) (*ProfileForecast, error) {
+	l := slog.With(slog.String("task", "resolveProfileFor"), slog.String("profile", profileName), slog.Int("gameVersion", gameVersion), slog.String("platform", string(platform)))
+
+	target, ok := platformTargets[platform]
+	if !ok {
+		return nil, fmt.Errorf("unknown platform %s", platform)
+	}
+	if gameVersion <= 0 {
+		return nil, fmt.Errorf("invalid game version %d", gameVersion)
+	}
+
+	profile := f.contextSnapshot().Profiles.GetProfile(profileName)
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s not found", profileName)
+	}
+	if !slices.Contains(profile.RequiredTargets, target) {
+		profile.RequiredTargets = append(slices.Clone(profile.RequiredTargets), target)
+	}
+
+	res := resolver.NewDependencyResolver(f.ficsitCli.Provider, viper.GetString("api-base"))
+
+	lockfile, err := profile.Resolve(res, nil, gameVersion)
+	if err != nil {
+		var solvingError resolver.DependencyResolverError
+		if errors.As(err, &solvingError) {
+			l.Info("profile can't be resolved", slog.Any("error", err))
+			return &ProfileForecast{
+				Conflict: newProfileConflict(solvingError.Error(), solvingError.Cause()),
+			}, nil
+		}
+		l.Error("failed to resolve profile", slog.Any("error", err))
+		return nil, fmt.Errorf("failed to resolve profile: %w", err)
+	}
+
+	return &ProfileForecast{
+		LockFile: lockfile,
+	}, nil
+}
+
+// resolverRootPkg is the package ficsit-resolver resolves the profile as, which depends on all of its mods
+const resolverRootPkg = "$$root$$"
+
+// newProfileConflict lists the mods involved in the incompatibility that made resolving a profile fail
+func newProfileConflict(message string, cause *pubgrub.Incompatibility) *ProfileConflict {
+	conflict := &ProfileConflict{
+		Message: message,
+		Mods:    []string{},
+	}
+	if cause == nil {
+		return conflict
+	}
+
+	visited := make(map[*pubgrub.Incompatibility]bool)
+	var visit func(incompatibility *pubgrub.Incompatibility)
+	visit = func(incompatibility *pubgrub.Incompatibility) {
+		if visited[incompatibility] {
+			return
+		}
+		visited[incompatibility] = true
+		for _, term := range incompatibility.Terms() {
+			switch pkg := term.Dependency(); pkg {
+			case resolverRootPkg:
+			case "FactoryGame":
+				conflict.GameVersion = true
+			default:
+				if !slices.Contains(conflict.Mods, pkg) {
+					conflict.Mods = append(conflict.Mods, pkg)
+				}
+			}
+		}
+		for _, cause := range incompatibility.Causes() {
+			visit(cause)
+		}
+	}
+	visit(cause)
+
+	slices.Sort(conflict.Mods)
+	return conflict
+}
@@ -0,0 +1,97 @@
+package ficsitcli
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/mircearoata/pubgrub-go/pubgrub"
+	"github.com/mircearoata/pubgrub-go/pubgrub/semver"
+)
+
+// conflictSource is a resolver source with canned packages, standing in for the mod repository
+type conflictSource map[string][]pubgrub.PackageVersion
+
+func (s conflictSource) GetPackageVersions(pkg string) ([]pubgrub.PackageVersion, error) {
+	if versions, ok := s[pkg]; ok {
+		return versions, nil
+	}
+	return nil, errors.New("package not found")
+}
+
+func (s conflictSource) PickVersion(_ string, versions []semver.Version) semver.Version {
+	return versions[len(versions)-1]
+}
+
+func mustVersion(t *testing.T, v string) semver.Version {
+	t.Helper()
+	version, err := semver.NewVersion(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return version
+}
+
+func mustConstraint(t *testing.T, c string) semver.Constraint {
+	t.Helper()
+	constraint, err := semver.NewConstraint(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return constraint
+}
+
+func TestNewProfileConflict(t *testing.T) {
+	// The profile has a mod that needs a newer game than the one it is resolved for,
+	// and one that is not involved in the conflict
+	source := conflictSource{
+		resolverRootPkg: {{
+			Version: mustVersion(t, "0.0.0"),
+			Dependencies: map[string]semver.Constraint{
+				"FactoryGame":  mustConstraint(t, "~365306.0.0"),
+				"RefinedPower": mustConstraint(t, ">=0.0.0"),
+				"PakUtility":   mustConstraint(t, ">=0.0.0"),
+			},
+		}},
+		"FactoryGame": {{Version: mustVersion(t, "365306.0.0")}},
+		"RefinedPower": {{
+			Version: mustVersion(t, "3.0.0"),
+			Dependencies: map[string]semver.Constraint{
+				"SML":         mustConstraint(t, "^3.8.0"),
+				"FactoryGame": mustConstraint(t, ">=383000"),
+			},
+		}},
+		"SML":        {{Version: mustVersion(t, "3.8.0")}},
+		"PakUtility": {{Version: mustVersion(t, "1.0.0")}},
+	}
+
+	_, err := pubgrub.Solve(source, resolverRootPkg)
+	var solvingError pubgrub.SolvingError
+	if !errors.As(err, &solvingError) {
+		t.Fatalf("expected a solving error, got %v", err)
+	}
+
+	conflict := newProfileConflict("canned message", solvingError.Cause())
+
+	if conflict.Message != "canned message" {
+		t.Errorf("Message = %q, expected the canned message", conflict.Message)
+	}
+	if !conflict.GameVersion {
+		t.Errorf("expected the game version to be one of the causes")
+	}
+	if expected := []string{"RefinedPower"}; !slices.Equal(conflict.Mods, expected) {
+		t.Errorf("Mods = %q, expected %q", conflict.Mods, expected)
+	}
+}
+
+func TestNewProfileConflictWithoutCause(t *testing.T) {
+	conflict := newProfileConflict("canned message", nil)
+	if conflict.Message != "canned message" || len(conflict.Mods) != 0 || conflict.GameVersion {
+		t.Errorf("unexpected conflict %+v", conflict)
+	}
+
+	conflict = newProfileConflict("canned message", &pubgrub.Incompatibility{})
+	if len(conflict.Mods) != 0 || conflict.GameVersion {
+		t.Errorf("unexpected conflict for an incompatibility without terms %+v", conflict)
+	}
+}
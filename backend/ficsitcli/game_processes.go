@@ -0,0 +1,229 @@
+package ficsitcli
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/gameprocess"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+const gameProcessPollInterval = 5 * time.Second
+
+// ErrGameRunning is returned by actions on an installation while its game or server is running,
+// since changing the mods under it corrupts loads, and fails on files that are in use
+var ErrGameRunning = errors.New("the game is running, close it before changing mods")
+
+// ErrActionQueued is returned by actions on an installation while its game or server is running, when they are queued instead,
+// since they have not happened yet. A queued action that fails later is reported in a queuedActionFailed event.
+var ErrActionQueued = errors.New("the game is running, the change will be made once it exits")
+
+func (f *ficsitCLI) StartGameRunningWatcher() {
+	gameRunningTicker := time.NewTicker(gameProcessPollInterval)
+	go func() {
+		for range gameRunningTicker.C {
+			f.updateRunningProcesses()
+		}
+	}()
+}
+
+// updateRunningProcesses finds the game processes that started or stopped since the last check.
+// The details of a process are only read when it is first seen, but processes without an installation
+// are matched again every time, since the installations may not have been known yet, such as at startup.
+func (f *ficsitCLI) updateRunningProcesses() {
+	processes, err := gameprocess.List()
+	if err != nil {
+		slog.Error("failed to get processes", slog.Any("error", err))
+		return
+	}
+
+	installationPaths := f.localInstallationPaths()
+
+	f.runningProcessesLock.Lock()
+	previous := f.runningProcesses
+	running := make(map[int]gameprocess.Process, len(processes))
+	started := make([]gameprocess.Process, 0)
+	matched := make([]gameprocess.Process, 0)
+	for _, process := range processes {
+		if known, ok := previous[process.PID]; ok && known.Executable == process.Executable {
+			if known.Installation == "" {
+				known.MatchInstallation(installationPaths)
+				if known.Installation != "" {
+					matched = append(matched, known)
+				}
+			}
+			running[process.PID] = known
+			continue
+		}
+		process.ReadDetails()
+		process.MatchInstallation(installationPaths)
+		running[process.PID] = process
+		started = append(started, process)
+	}
+	stopped := make([]gameprocess.Process, 0)
+	for pid, process := range previous {
+		if _, ok := running[pid]; !ok {
+			stopped = append(stopped, process)
+		}
+	}
+	f.runningProcesses = running
+	for path := range f.ignoreRunningGame {
+		if !f.isInstallationRunningLocked(path) {
+			// The override only lasts until the game exits
+			delete(f.ignoreRunningGame, path)
+		}
+	}
+	f.runningProcessesLock.Unlock()
+
+	for _, process := range started {
+		slog.Info("game process started", slog.Int("pid", process.PID), slog.String("executable", process.Executable), slog.String("path", process.Path), slog.String("installation", process.Installation))
+		wailsRuntime.EventsEmit(appCommon.AppContext, "gameProcessStarted", process)
+	}
+	for _, process := range stopped {
+		slog.Info("game process stopped", slog.Int("pid", process.PID), slog.String("executable", process.Executable), slog.String("installation", process.Installation))
+		wailsRuntime.EventsEmit(appCommon.AppContext, "gameProcessStopped", process)
+	}
+	for _, process := range matched {
+		slog.Info("game process matched to installation", slog.Int("pid", process.PID), slog.String("executable", process.Executable), slog.String("installation", process.Installation))
+	}
+	if len(started) > 0 || len(stopped) > 0 || len(matched) > 0 {
+		wailsRuntime.EventsEmit(appCommon.AppContext, "runningProcesses", f.GetRunningProcesses())
+	}
+	wailsRuntime.EventsEmit(appCommon.AppContext, "isGameRunning", f.IsGameRunning())
+	f.emitBlockedInstallations()
+}
+
+func (f *ficsitCLI) localInstallationPaths() []string {
+	paths := make([]string, 0)
+	f.installationMetadata.Range(func(path string, meta installationMetadata) bool {
+		if meta.Info != nil && meta.Info.Location == common.LocationTypeLocal {
+			paths = append(paths, path)
+		}
+		return true
+	})
+	return paths
+}
+
+// GetRunningProcesses returns the game clients and dedicated servers that are running, with the installation they belong to, if known
+func (f *ficsitCLI) GetRunningProcesses() []gameprocess.Process {
+	f.runningProcessesLock.RLock()
+	defer f.runningProcessesLock.RUnlock()
+	processes := make([]gameprocess.Process, 0, len(f.runningProcesses))
+	for _, process := range f.runningProcesses {
+		processes = append(processes, process)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].PID < processes[j].PID
+	})
+	return processes
+}
+
+// IsInstallationRunning returns whether a game client or dedicated server of the installation is running
+func (f *ficsitCLI) IsInstallationRunning(path string) bool {
+	f.runningProcessesLock.RLock()
+	defer f.runningProcessesLock.RUnlock()
+	return f.isInstallationRunningLocked(path)
+}
+
+func (f *ficsitCLI) isInstallationRunningLocked(path string) bool {
+	for _, process := range f.runningProcesses {
+		if process.Installation == path {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnmatchedGameRunning returns whether a game client or dedicated server is running, whose installation is not known
+func (f *ficsitCLI) isUnmatchedGameRunning() bool {
+	f.runningProcessesLock.RLock()
+	defer f.runningProcessesLock.RUnlock()
+	for _, process := range f.runningProcesses {
+		if process.Installation == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGameRunning returns whether the selected installation is running.
+// A game client whose installation is not known could be the selected installation, so it counts as well.
+func (f *ficsitCLI) IsGameRunning() bool {
+	selectedInstallation := f.getSelectedInstallationPath()
+	f.runningProcessesLock.RLock()
+	defer f.runningProcessesLock.RUnlock()
+	for _, process := range f.runningProcesses {
+		if process.Installation == "" {
+			if !process.Server {
+				return true
+			}
+			continue
+		}
+		if process.Installation == selectedInstallation {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInstallationBlocked returns whether mod changes to the installation are blocked, because its game is running
+func (f *ficsitCLI) IsInstallationBlocked(path string) bool {
+	f.runningProcessesLock.RLock()
+	defer f.runningProcessesLock.RUnlock()
+	return f.isInstallationBlockedLocked(path)
+}
+
+func (f *ficsitCLI) isInstallationBlockedLocked(path string) bool {
+	return !f.ignoreRunningGame[path] && f.isInstallationRunningLocked(path)
+}
+
+// GetBlockedInstallations returns the installations whose mods can't be changed, because their game is running
+func (f *ficsitCLI) GetBlockedInstallations() []string {
+	f.runningProcessesLock.RLock()
+	defer f.runningProcessesLock.RUnlock()
+	return f.getBlockedInstallationsLocked()
+}
+
+func (f *ficsitCLI) getBlockedInstallationsLocked() []string {
+	blocked := make([]string, 0)
+	for _, process := range f.runningProcesses {
+		if process.Installation != "" && !slices.Contains(blocked, process.Installation) && f.isInstallationBlockedLocked(process.Installation) {
+			blocked = append(blocked, process.Installation)
+		}
+	}
+	slices.Sort(blocked)
+	return blocked
+}
+
+// SetIgnoreRunningGame allows changing the mods of the installation while its game is running, until the game exits
+func (f *ficsitCLI) SetIgnoreRunningGame(path string, ignore bool) {
+	f.runningProcessesLock.Lock()
+	if ignore {
+		f.ignoreRunningGame[path] = true
+	} else {
+		delete(f.ignoreRunningGame, path)
+	}
+	f.runningProcessesLock.Unlock()
+
+	slog.Info("changed running game override", slog.String("path", path), slog.Bool("ignore", ignore))
+	f.emitBlockedInstallations()
+}
+
+// emitBlockedInstallations sends the blockedInstallations event if the blocked installations changed
+func (f *ficsitCLI) emitBlockedInstallations() {
+	f.runningProcessesLock.Lock()
+	blocked := f.getBlockedInstallationsLocked()
+	changed := !slices.Equal(blocked, f.blockedInstallations)
+	f.blockedInstallations = blocked
+	f.runningProcessesLock.Unlock()
+
+	if changed && appCommon.AppContext != nil {
+		wailsRuntime.EventsEmit(appCommon.AppContext, "blockedInstallations", blocked)
+	}
+}
@@ -0,0 +1,340 @@
+package ficsitcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	resolver "github.com/satisfactorymodding/ficsit-resolver"
+	"github.com/spf13/viper"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
+)
+
+// GameVersionChange is sent with the gameVersionChanged event when the game of an installation was updated since SMM last found it
+type GameVersionChange struct {
+	Path            string `json:"path"`
+	PreviousVersion int    `json:"previousVersion"`
+	Version         int    `json:"version"`
+	// IncompatibleMods are the installed mods that can't be used with the new game version as they are
+	IncompatibleMods []IncompatibleMod `json:"incompatibleMods"`
+	// CheckError is set if the mods could not be checked, such as when offline
+	CheckError string `json:"checkError,omitempty"`
+}
+
+type IncompatibleMod struct {
+	Mod            string `json:"mod"`
+	CurrentVersion string `json:"currentVersion"`
+	// NewVersion is the version that is compatible with the new game version, if any.
+	// If empty, the mod can only be disabled.
+	NewVersion string `json:"newVersion,omitempty"`
+}
+
+// checkGameVersions compares the game version of the valid installations with the one last seen,
+// and checks the mods of the installations whose game was updated in the background
+func (f *ficsitCLI) checkGameVersions() {
+	f.installationMetadata.Range(func(path string, meta installationMetadata) bool {
+		if meta.State != InstallStateValid || meta.Info == nil {
+			return true
+		}
+		f.checkGameVersion(path, meta.Info.Version)
+		return true
+	})
+}
+
+// checkGameVersion reports the game update of the installation, if its version is not the one last seen.
+// The version is only recorded as seen once the mods were checked and nothing has to be done about them,
+// or the change was dismissed or fixed, so a change is reported again if SMM is closed before that.
+func (f *ficsitCLI) checkGameVersion(path string, version int) {
+	if version == 0 {
+		return
+	}
+	previousVersion := settings.GetInstallationSettings(path).LastSeenGameVersion
+	if previousVersion == version {
+		return
+	}
+
+	if previousVersion == 0 {
+		// The installation was not seen before, so there is nothing to compare to
+		f.markGameVersionSeen(path, version)
+		return
+	}
+
+	if change, ok := f.gameVersionChanges.Load(path); ok && change.Version == version {
+		// Already reported, waiting for the user
+		return
+	}
+	// A check of an older version still running is superseded, and does not report its result
+	if checkingVersion, checking := f.gameVersionChecks.LoadAndStore(path, version); checking && checkingVersion == version {
+		return
+	}
+
+	go f.reportGameVersionChange(path, previousVersion, version)
+}
+
+func (f *ficsitCLI) markGameVersionSeen(path string, version int) {
+	err := settings.UpdateInstallationSettings(path, func(installationSettings *settings.InstallationSettings) {
+		installationSettings.LastSeenGameVersion = version
+	})
+	if err != nil {
+		slog.Error("failed to save last seen game version", slog.String("path", path), slog.Any("error", err))
+	}
+}
+
+func (f *ficsitCLI) reportGameVersionChange(path string, previousVersion int, version int) {
+	l := slog.With(slog.String("task", "reportGameVersionChange"), slog.String("path", path), slog.Int("previousVersion", previousVersion), slog.Int("version", version))
+	l.Info("game version changed")
+	defer f.gameVersionChecks.Compute(path, func(checkingVersion int, checking bool) (int, bool) {
+		// Only remove the entry of this check, not of a newer one that started since
+		return checkingVersion, !checking || checkingVersion == version
+	})
+
+	change := GameVersionChange{
+		Path:             path,
+		PreviousVersion:  previousVersion,
+		Version:          version,
+		IncompatibleMods: []IncompatibleMod{},
+	}
+
+	installation := f.GetInstallation(path)
+	if installation == nil {
+		return
+	}
+	incompatibleMods, err := f.findIncompatibleMods(installation, version)
+	if err != nil {
+		l.Error("failed to check mod compatibility", slog.Any("error", err))
+		change.CheckError = err.Error()
+	} else {
+		change.IncompatibleMods = incompatibleMods
+	}
+
+	if checkingVersion, _ := f.gameVersionChecks.Load(path); checkingVersion != version {
+		l.Info("game version changed again during the check, not reporting it")
+		return
+	}
+
+	if len(change.IncompatibleMods) > 0 {
+		l.Warn("mods incompatible with the new game version", slog.Int("count", len(change.IncompatibleMods)))
+	} else if change.CheckError == "" {
+		f.markGameVersionSeen(path, version)
+	}
+
+	f.gameVersionChanges.Store(path, change)
+
+	if appCommon.AppContext != nil {
+		wailsRuntime.EventsEmit(appCommon.AppContext, "gameVersionChanged", change)
+	}
+	f.emitGameVersionChanges()
+}
+
+func (f *ficsitCLI) emitGameVersionChanges() {
+	if appCommon.AppContext == nil {
+		return
+	}
+	wailsRuntime.EventsEmit(appCommon.AppContext, "gameVersionChanges", f.GetGameVersionChanges())
+}
+
+// getIncompatibleModsChange returns the game update of the installation that made mods incompatible, if it was not dismissed or fixed yet
+func (f *ficsitCLI) getIncompatibleModsChange(path string) (GameVersionChange, bool) {
+	change, ok := f.gameVersionChanges.Load(path)
+	if !ok || len(change.IncompatibleMods) == 0 {
+		return GameVersionChange{}, false
+	}
+	return change, true
+}
+
+// findIncompatibleMods resolves the profile of the installation against gameVersion,
+// returning the installed mods that would have to change
+func (f *ficsitCLI) findIncompatibleMods(installation *cli.Installation, gameVersion int) ([]IncompatibleMod, error) {
+	lockfile, err := installation.LockFile(f.contextSnapshot())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get lockfile: %w", err)
+	}
+	if lockfile == nil {
+		return []IncompatibleMod{}, nil
+	}
+
+	ctx := f.installContext(installation)
+	profileName := f.getInstallationProfile(installation)
+	profile := ctx.Profiles.GetProfile(profileName)
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s not found", profileName)
+	}
+
+	res := resolver.NewDependencyResolver(f.ficsitCli.Provider, viper.GetString("api-base"))
+
+	incompatibleMods := []IncompatibleMod{}
+
+	newLockfile, err := profile.Resolve(res, lockfile, gameVersion)
+	if err == nil {
+		// The installed version of a mod is kept while it is compatible, so any other version means it is not
+		for modReference, lockedMod := range lockfile.Mods {
+			newLockedMod, ok := newLockfile.Mods[modReference]
+			if ok && newLockedMod.Version != lockedMod.Version {
+				incompatibleMods = append(incompatibleMods, IncompatibleMod{
+					Mod:            modReference,
+					CurrentVersion: lockedMod.Version,
+					NewVersion:     newLockedMod.Version,
+				})
+			}
+		}
+		sortIncompatibleMods(incompatibleMods)
+		return incompatibleMods, nil
+	}
+	var solvingError resolver.DependencyResolverError
+	if !errors.As(err, &solvingError) {
+		return nil, fmt.Errorf("failed to resolve profile: %w", err)
+	}
+
+	// The profile can't be resolved as a whole, so find out which mods are the problem
+	for modReference, mod := range profile.Mods {
+		lockedMod, installed := lockfile.Mods[modReference]
+		if !mod.Enabled || !installed {
+			continue
+		}
+		newVersion, err := resolveModVersion(res, modReference, mod.Version, lockfile, gameVersion, profile.RequiredTargets)
+		if err != nil {
+			return nil, err
+		}
+		if newVersion == lockedMod.Version {
+			continue
+		}
+		if newVersion == "" {
+			// Nothing matches the version constraint of the profile, so see if updating past it helps
+			newVersion, err = resolveModVersion(res, modReference, ">=0.0.0", lockfile, gameVersion, profile.RequiredTargets)
+			if err != nil {
+				return nil, err
+			}
+		}
+		incompatibleMods = append(incompatibleMods, IncompatibleMod{
+			Mod:            modReference,
+			CurrentVersion: lockedMod.Version,
+			NewVersion:     newVersion,
+		})
+	}
+	sortIncompatibleMods(incompatibleMods)
+	return incompatibleMods, nil
+}
+
+// resolveModVersion returns the version of the mod that would be installed if it was the only one, or an empty string if there is none
+func resolveModVersion(res resolver.DependencyResolver, modReference string, constraint string, lockfile *resolver.LockFile, gameVersion int, requiredTargets []resolver.TargetName) (string, error) {
+	result, err := res.ResolveModDependencies(context.TODO(), map[string]string{modReference: constraint}, lockfile, gameVersion, requiredTargets)
+	if err != nil {
+		var solvingError resolver.DependencyResolverError
+		if errors.As(err, &solvingError) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to resolve %s: %w", modReference, err)
+	}
+	return result.Mods[modReference].Version, nil
+}
+
+func sortIncompatibleMods(mods []IncompatibleMod) {
+	sort.Slice(mods, func(i, j int) bool {
+		return strings.ToLower(mods[i].Mod) < strings.ToLower(mods[j].Mod)
+	})
+}
+
+// GetGameVersionChanges returns the game updates that were noticed, but not dismissed yet.
+// Changes noticed at startup happen before the frontend listens for the gameVersionChanged event.
+func (f *ficsitCLI) GetGameVersionChanges() []GameVersionChange {
+	changes := make([]GameVersionChange, 0)
+	f.gameVersionChanges.Range(func(_ string, change GameVersionChange) bool {
+		changes = append(changes, change)
+		return true
+	})
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// DismissGameVersionChange keeps the mods of the installation as they are after a game update
+func (f *ficsitCLI) DismissGameVersionChange(path string) {
+	change, ok := f.gameVersionChanges.LoadAndDelete(path)
+	if !ok {
+		return
+	}
+	f.markGameVersionSeen(path, change.Version)
+	f.emitGameVersionChanges()
+}
+
+// FixIncompatibleMods updates and disables the mods reported as incompatible after a game update.
+// Mods are disabled only for the installation, since the profile may be used by installations with another game version.
+func (f *ficsitCLI) FixIncompatibleMods(path string, update []string, disable []string) error {
+	installation := f.GetInstallation(path)
+	if installation == nil || !f.isValidInstall(path) {
+		return fmt.Errorf("invalid installation: %s", path)
+	}
+
+	return f.action(installation, ActionUpdate, noItem, func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
+		if len(disable) > 0 {
+			err := settings.UpdateInstallationSettings(path, func(installationSettings *settings.InstallationSettings) {
+				if installationSettings.ModOverrides == nil {
+					installationSettings.ModOverrides = make(map[string]settings.ModOverride)
+				}
+				for _, modReference := range disable {
+					override := installationSettings.ModOverrides[modReference]
+					enabled := false
+					override.Enabled = &enabled
+					installationSettings.ModOverrides[modReference] = override
+				}
+			})
+			if err != nil {
+				l.Error("failed to save mod overrides", slog.Any("error", err))
+				return fmt.Errorf("failed to save mod overrides: %w", err)
+			}
+		}
+
+		update = slices.DeleteFunc(slices.Clone(update), func(modReference string) bool {
+			return slices.Contains(disable, modReference)
+		})
+		if len(update) > 0 {
+			err := f.updateProfile(l, f.getInstallationProfile(installation), func(profile *cli.Profile) error {
+				for _, modReference := range update {
+					if _, ok := profile.Mods[modReference]; !ok {
+						l.Warn("mod not found in profile", slog.String("mod", modReference))
+						continue
+					}
+					profile.Mods[modReference] = cli.ProfileMod{
+						Enabled: profile.Mods[modReference].Enabled,
+						Version: ">=0.0.0",
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			err = installation.UpdateMods(f.installContext(installation), update)
+			if err != nil {
+				l.Error("failed to update mods", slog.Any("error", err))
+				var solvingError resolver.DependencyResolverError
+				if errors.As(err, &solvingError) {
+					return solvingError
+				}
+				return err //nolint:wrapcheck
+			}
+		}
+
+		err := f.validateInstall(installation, taskUpdates)
+		if err != nil {
+			l.Error("failed to validate installation", slog.Any("error", err))
+			return err
+		}
+
+		if change, ok := f.gameVersionChanges.LoadAndDelete(path); ok {
+			f.markGameVersionSeen(path, change.Version)
+			f.emitGameVersionChanges()
+		}
+		return nil
+	})
+}
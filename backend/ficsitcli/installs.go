@@ -4,27 +4,37 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"os/exec"
+	"maps"
+	"slices"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 	resolver "github.com/satisfactorymodding/ficsit-resolver"
 
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
 )
 
 func (f *ficsitCLI) initInstallations() error {
-	for _, install := range f.ficsitCli.Installations.Installations {
+	err := f.initRemoteCredentials()
+	if err != nil {
+		return fmt.Errorf("failed to initialize remote server credentials: %w", err)
+	}
+
+	for _, install := range f.getInstallationsSnapshot() {
 		f.installationMetadata.Store(install.Path, installationMetadata{
 			State: InstallStateUnknown,
 			Info:  nil,
 		})
 	}
 
-	err := f.initLocalInstallationsMetadata()
+	err = f.initLocalInstallationsMetadata()
 	if err != nil {
 		return fmt.Errorf("failed to initialize found installations: %w", err)
 	}
 
+	f.checkGameVersions()
+
 	// This may take a while, so we do it in the background
 	go f.initRemoteServerInstallationsMetadata()
 
@@ -35,32 +45,43 @@ func (f *ficsitCLI) initInstallations() error {
 }
 
 func (f *ficsitCLI) ensureSelectedInstallationIsValid() {
-	if !f.isValidInstall(f.ficsitCli.Installations.SelectedInstallation) {
+	if !f.isValidInstall(f.getSelectedInstallationPath()) {
 		filteredInstalls := f.GetInstallations()
 		if len(filteredInstalls) > 0 {
-			f.ficsitCli.Installations.SelectedInstallation = filteredInstalls[0]
-			err := f.ficsitCli.Installations.Save()
-			if err != nil {
-				slog.Error("failed to save selected installation", slog.Any("error", err))
-			}
+			f.updateInstallations(slog.Default(), func() {
+				f.ficsitCli.Installations.SelectedInstallation = filteredInstalls[0]
+			})
 			f.EmitGlobals()
 		}
 	}
 }
 
+// GetInstallations returns the valid installations that are not hidden, in the order set by the user
 func (f *ficsitCLI) GetInstallations() []string {
-	installations := make([]string, 0, len(f.ficsitCli.Installations.Installations))
-	for _, installation := range f.ficsitCli.Installations.Installations {
+	return f.getValidInstallations(false)
+}
+
+func (f *ficsitCLI) GetHiddenInstallations() []string {
+	return f.getValidInstallations(true)
+}
+
+func (f *ficsitCLI) getValidInstallations(hidden bool) []string {
+	snapshot := f.getInstallationsSnapshot()
+	installations := make([]string, 0, len(snapshot))
+	for _, installation := range snapshot {
 		if !f.isValidInstall(installation.Path) {
 			continue
 		}
+		if settings.GetInstallationSettings(installation.Path).Hidden != hidden {
+			continue
+		}
 		installations = append(installations, installation.Path)
 	}
-	return installations
+	return f.sortInstallations(installations)
 }
 
 func (f *ficsitCLI) GetInstallationsMetadata() map[string]installationMetadata {
-	rawMap := make(map[string]installationMetadata, len(f.ficsitCli.Installations.Installations))
+	rawMap := make(map[string]installationMetadata, f.installationMetadata.Size())
 	f.installationMetadata.Range(func(key string, value installationMetadata) bool {
 		rawMap[key] = value
 		return true
@@ -69,11 +90,19 @@ func (f *ficsitCLI) GetInstallationsMetadata() map[string]installationMetadata {
 }
 
 func (f *ficsitCLI) GetCurrentInstallationMetadata() installationMetadata {
-	meta, _ := f.installationMetadata.Load(f.ficsitCli.Installations.SelectedInstallation)
+	meta, _ := f.installationMetadata.Load(f.getSelectedInstallationPath())
 	return meta
 }
 
+func (f *ficsitCLI) setInstallFindErrors(findErrors []error) {
+	f.installFindErrorsLock.Lock()
+	defer f.installFindErrorsLock.Unlock()
+	f.installFindErrors = findErrors
+}
+
 func (f *ficsitCLI) GetInvalidInstalls() []string {
+	f.installFindErrorsLock.RLock()
+	defer f.installFindErrorsLock.RUnlock()
 	result := []string{}
 	for _, err := range f.installFindErrors {
 		var installFindErr common.InstallFindError
@@ -84,34 +113,58 @@ func (f *ficsitCLI) GetInvalidInstalls() []string {
 	return result
 }
 
+// GetInstallFinderDiagnostics returns how long each launcher took to find installations at startup, and what went wrong
+func (f *ficsitCLI) GetInstallFinderDiagnostics() []installfinders.FinderDiagnostic {
+	return installfinders.GetDiagnostics()
+}
+
 func (f *ficsitCLI) GetInstallation(path string) *cli.Installation {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
 	return f.ficsitCli.Installations.GetInstallation(path)
 }
 
+// getInstallationsSnapshot returns a copy of the installations list,
+// so it can be iterated while installations are added or removed
+func (f *ficsitCLI) getInstallationsSnapshot() []*cli.Installation {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	return slices.Clone(f.ficsitCli.Installations.Installations)
+}
+
+func (f *ficsitCLI) getSelectedInstallationPath() string {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	return f.ficsitCli.Installations.SelectedInstallation
+}
+
+// getInstallationProfile returns the profile of the installation, which can be changed by operations on it
+func (f *ficsitCLI) getInstallationProfile(installation *cli.Installation) string {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	return installation.Profile
+}
+
 func (f *ficsitCLI) SelectInstall(path string) error {
-	return f.action(ActionSelectInstall, newSimpleItem(path), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
-		if !f.isValidInstall(path) {
-			return fmt.Errorf("invalid installation: %s", path)
-		}
-		if f.ficsitCli.Installations.SelectedInstallation == path {
+	if !f.isValidInstall(path) {
+		return fmt.Errorf("invalid installation: %s", path)
+	}
+	installation := f.GetInstallation(path)
+	if installation == nil {
+		return fmt.Errorf("installation %s not found", path)
+	}
+	return f.action(installation, ActionSelectInstall, newSimpleItem(path), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
+		if f.getSelectedInstallationPath() == path {
 			return nil
 		}
-		installation := f.ficsitCli.Installations.GetInstallation(path)
-		if installation == nil {
-			return fmt.Errorf("installation %s not found", path)
-		}
 
-		f.ficsitCli.Installations.SelectedInstallation = path
-		err := f.ficsitCli.Installations.Save()
-		if err != nil {
-			l.Error("failed to save selected installation", slog.Any("error", err))
-		}
-
-		selectedInstallation := f.GetSelectedInstall()
+		f.updateInstallations(l, func() {
+			f.ficsitCli.Installations.SelectedInstallation = path
+		})
 
 		f.EmitGlobals()
 
-		installErr := f.validateInstall(selectedInstallation, taskUpdates)
+		installErr := f.validateInstall(installation, taskUpdates)
 
 		if installErr != nil {
 			l.Error("failed to validate install", slog.Any("error", installErr))
@@ -121,31 +174,42 @@ func (f *ficsitCLI) SelectInstall(path string) error {
 	})
 }
 
+// updateInstallations applies change while holding profilesLock, then saves the installations
+func (f *ficsitCLI) updateInstallations(l *slog.Logger, change func()) {
+	f.profilesLock.Lock()
+	defer f.profilesLock.Unlock()
+
+	change()
+
+	err := f.ficsitCli.Installations.Save()
+	if err != nil {
+		l.Error("failed to save installations", slog.Any("error", err))
+	}
+}
+
 func (f *ficsitCLI) GetSelectedInstall() *cli.Installation {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
 	return f.ficsitCli.Installations.GetInstallation(f.ficsitCli.Installations.SelectedInstallation)
 }
 
 func (f *ficsitCLI) SetModsEnabled(enabled bool) error {
+	selectedInstallation := f.GetSelectedInstall()
+
+	if selectedInstallation == nil {
+		return fmt.Errorf("no installation selected")
+	}
+
 	var item ProgressItem
 	if enabled {
 		item = newSimpleItem("true")
 	} else {
 		item = newSimpleItem("false")
 	}
-	return f.action(ActionToggleMods, item, func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
-		selectedInstallation := f.GetSelectedInstall()
-
-		if selectedInstallation == nil {
-			return fmt.Errorf("no installation selected")
-		}
-
-		l = l.With(slog.String("install", selectedInstallation.Path))
-
-		selectedInstallation.Vanilla = !enabled
-		err := f.ficsitCli.Installations.Save()
-		if err != nil {
-			l.Error("failed to save vanilla state of install", slog.Any("error", err))
-		}
+	return f.action(selectedInstallation, ActionToggleMods, item, func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
+		f.updateInstallations(l, func() {
+			selectedInstallation.Vanilla = !enabled
+		})
 
 		f.EmitGlobals()
 
@@ -162,7 +226,12 @@ func (f *ficsitCLI) SetModsEnabled(enabled bool) error {
 
 func (f *ficsitCLI) GetModsEnabled() bool {
 	selectedInstallation := f.GetSelectedInstall()
-	return selectedInstallation == nil || !selectedInstallation.Vanilla
+	if selectedInstallation == nil {
+		return true
+	}
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	return !selectedInstallation.Vanilla
 }
 
 func (f *ficsitCLI) GetSelectedInstallProfileMods() map[string]cli.ProfileMod {
@@ -170,11 +239,13 @@ func (f *ficsitCLI) GetSelectedInstallProfileMods() map[string]cli.ProfileMod {
 	if selectedInstallation == nil {
 		return make(map[string]cli.ProfileMod)
 	}
-	profile := f.GetProfile(selectedInstallation.Profile)
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	profile := f.getProfile(selectedInstallation.Profile)
 	if profile == nil {
 		return make(map[string]cli.ProfileMod)
 	}
-	return profile.Mods
+	return maps.Clone(profile.Mods)
 }
 
 func (f *ficsitCLI) GetSelectedInstallLockfileMods() (map[string]resolver.LockedMod, error) {
@@ -182,7 +253,7 @@ func (f *ficsitCLI) GetSelectedInstallLockfileMods() (map[string]resolver.Locked
 	if selectedInstallation == nil {
 		return make(map[string]resolver.LockedMod), nil
 	}
-	lockfile, err := selectedInstallation.LockFile(f.ficsitCli)
+	lockfile, err := selectedInstallation.LockFile(f.contextSnapshot())
 	if err != nil {
 		return nil, err //nolint:wrapcheck
 	}
@@ -197,28 +268,9 @@ func (f *ficsitCLI) GetSelectedInstallLockfile() (*resolver.LockFile, error) {
 	if selectedInstallation == nil {
 		return nil, nil
 	}
-	lockfile, err := selectedInstallation.LockFile(f.ficsitCli)
+	lockfile, err := selectedInstallation.LockFile(f.contextSnapshot())
 	if err != nil {
 		return nil, err //nolint:wrapcheck
 	}
 	return lockfile, nil
 }
-
-func (f *ficsitCLI) LaunchGame() {
-	selectedInstallation := f.GetSelectedInstall()
-	if selectedInstallation == nil {
-		slog.Error("no installation selected")
-		return
-	}
-	metadata, ok := f.installationMetadata.Load(selectedInstallation.Path)
-	if !ok || metadata.Info == nil {
-		slog.Error("no metadata for installation")
-		return
-	}
-	cmd := exec.Command(metadata.Info.LaunchPath[0], metadata.Info.LaunchPath[1:]...)
-	out, err := cmd.CombinedOutput()
-	if err != nil {
-		slog.Error("failed to launch game", slog.Any("error", err), slog.String("cmd", cmd.String()), slog.String("output", string(out)))
-		return
-	}
-}
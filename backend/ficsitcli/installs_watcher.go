@@ -0,0 +1,167 @@
+package ficsitcli
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+// Launchers usually write several manifests when installing or moving the game,
+// so the rescan waits until they have been quiet for a while
+const rescanDelay = 2 * time.Second
+
+// watchRetryInterval is how often the manifest directories that don't exist yet are checked again,
+// since fsnotify can only watch existing directories, and a launcher may be installed after SMM started
+const watchRetryInterval = time.Minute
+
+// StartInstallationsWatcher rescans the local installations when the launchers change their manifests,
+// so installing or moving the game does not require restarting SMM
+func (f *ficsitCLI) StartInstallationsWatcher() {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Error("failed to create installations watcher", slog.Any("error", err))
+		return
+	}
+	f.installationsWatcher = watcher
+	watchManifestDirs(watcher)
+
+	// Launchers that were too slow at startup report their installations later
+	lateResults := make(chan struct{}, 1)
+	installfinders.OnLateResult(func() {
+		select {
+		case lateResults <- struct{}{}:
+		default:
+		}
+	})
+
+	go func() {
+		retryWatch := time.NewTicker(watchRetryInterval)
+		defer retryWatch.Stop()
+		var rescan <-chan time.Time
+		for {
+			select {
+			case <-lateResults:
+				rescan = time.After(rescanDelay)
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op == fsnotify.Chmod || !common.IsManifest(event.Name) {
+					continue
+				}
+				rescan = time.After(rescanDelay)
+			case <-retryWatch.C:
+				watchManifestDirs(watcher)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				slog.Warn("installations watcher error", slog.Any("error", err))
+			case <-rescan:
+				rescan = nil
+				f.rescanLocalInstallations()
+				// New steam libraries may have been added
+				watchManifestDirs(watcher)
+			}
+		}
+	}()
+}
+
+func (f *ficsitCLI) StopInstallationsWatcher() {
+	if f.installationsWatcher == nil {
+		return
+	}
+	installfinders.OnLateResult(nil)
+	err := f.installationsWatcher.Close()
+	if err != nil {
+		slog.Warn("failed to stop installations watcher", slog.Any("error", err))
+	}
+}
+
+func watchManifestDirs(watcher *fsnotify.Watcher) {
+	watched := watcher.WatchList()
+	for _, dir := range common.GetManifestDirs() {
+		if slices.Contains(watched, dir) {
+			continue
+		}
+		err := watcher.Add(dir)
+		if err != nil {
+			slog.Debug("failed to watch launcher manifests", slog.String("dir", dir), slog.Any("error", err))
+		}
+	}
+}
+
+// rescanLocalInstallations finds the local installations again, adding the new ones,
+// and marking the ones that are not found anymore as invalid
+func (f *ficsitCLI) rescanLocalInstallations() {
+	l := slog.With(slog.String("task", "rescanLocalInstallations"))
+
+	installs, findErrors := findLocalInstallations()
+	f.setInstallFindErrors(findErrors)
+
+	fallbackProfile := f.GetFallbackProfile()
+
+	found := make(map[string]bool, len(installs))
+	added := make([]string, 0)
+	for _, install := range installs {
+		if existingPath, ok := f.existingInstallationPath(install.Path); ok {
+			install.Path = existingPath
+		} else {
+			var err error
+			f.updateInstallations(l, func() {
+				_, err = f.ficsitCli.Installations.AddInstallation(f.ficsitCli, install.Path, fallbackProfile)
+			})
+			if err != nil {
+				l.Error("failed to add installation", slog.String("path", install.Path), slog.Any("error", err))
+				continue
+			}
+		}
+		found[install.Path] = true
+
+		if previous, ok := f.installationMetadata.Load(install.Path); !ok || previous.State != InstallStateValid {
+			added = append(added, install.Path)
+		}
+		f.installationMetadata.Store(install.Path, installationMetadata{
+			State: InstallStateValid,
+			Info:  install,
+		})
+		// Launchers change their manifests when they update the game
+		f.checkGameVersion(install.Path, install.Version)
+	}
+
+	missing := make([]string, 0)
+	f.installationMetadata.Range(func(path string, meta installationMetadata) bool {
+		if meta.State != InstallStateValid || meta.Info == nil || meta.Info.Location != common.LocationTypeLocal || found[path] {
+			return true
+		}
+		missing = append(missing, path)
+		f.installationMetadata.Store(path, installationMetadata{
+			State: InstallStateInvalid,
+			Info:  meta.Info,
+		})
+		return true
+	})
+
+	if len(added) == 0 && len(missing) == 0 {
+		return
+	}
+	l.Info("installations changed", slog.Any("added", added), slog.Any("missing", missing))
+
+	f.ensureSelectedInstallationIsValid()
+	f.EmitGlobals()
+	if appCommon.AppContext != nil {
+		if len(added) > 0 {
+			wailsRuntime.EventsEmit(appCommon.AppContext, "installationsFound", added)
+		}
+		if len(missing) > 0 {
+			wailsRuntime.EventsEmit(appCommon.AppContext, "installationsMissing", missing)
+		}
+	}
+}
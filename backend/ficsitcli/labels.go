@@ -0,0 +1,137 @@
+package ficsitcli
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
+)
+
+var colorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type InstallationLabel struct {
+	// Name is the label if set, otherwise the launcher name
+	Name   string `json:"name"`
+	Label  string `json:"label,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Order  int    `json:"order,omitempty"`
+	Hidden bool   `json:"hidden,omitempty"`
+}
+
+func (f *ficsitCLI) GetInstallationLabel(path string) InstallationLabel {
+	installationSettings := settings.GetInstallationSettings(path)
+	label := InstallationLabel{
+		Name:   installationSettings.Label,
+		Label:  installationSettings.Label,
+		Color:  installationSettings.Color,
+		Order:  installationSettings.Order,
+		Hidden: installationSettings.Hidden,
+	}
+	if label.Name == "" {
+		label.Name = path
+		if meta, ok := f.installationMetadata.Load(path); ok && meta.Info != nil && meta.Info.Launcher != "" {
+			label.Name = meta.Info.Launcher
+		}
+	}
+	return label
+}
+
+// GetInstallationLabels returns the labels of all valid installations, including the hidden ones
+func (f *ficsitCLI) GetInstallationLabels() map[string]InstallationLabel {
+	labels := make(map[string]InstallationLabel)
+	for _, installation := range f.getInstallationsSnapshot() {
+		if !f.isValidInstall(installation.Path) {
+			continue
+		}
+		labels[installation.Path] = f.GetInstallationLabel(installation.Path)
+	}
+	return labels
+}
+
+// SetInstallationLabel sets the name and color shown for the installation. Empty values reset them to the default.
+func (f *ficsitCLI) SetInstallationLabel(path string, label string, color string) error {
+	if color != "" && !colorRegex.MatchString(color) {
+		return fmt.Errorf("invalid color: %s, expected #rrggbb", color)
+	}
+	return f.updateInstallationSettings(path, func(installationSettings *settings.InstallationSettings) {
+		installationSettings.Label = label
+		installationSettings.Color = color
+	})
+}
+
+func (f *ficsitCLI) SetInstallationHidden(path string, hidden bool) error {
+	return f.updateInstallationSettings(path, func(installationSettings *settings.InstallationSettings) {
+		installationSettings.Hidden = hidden
+	})
+}
+
+// SetInstallationsOrder moves the installations to the start of the list, in the given order.
+// Installations that are not given keep their relative order after them.
+func (f *ficsitCLI) SetInstallationsOrder(paths []string) error {
+	for _, path := range paths {
+		if f.GetInstallation(path) == nil {
+			return fmt.Errorf("installation %s not found", path)
+		}
+	}
+	ordered := f.sortInstallations(f.getAllInstallations())
+	ordered = slices.DeleteFunc(ordered, func(path string) bool {
+		return slices.Contains(paths, path)
+	})
+	ordered = append(slices.Clone(paths), ordered...)
+
+	for i, path := range ordered {
+		err := settings.UpdateInstallationSettings(path, func(installationSettings *settings.InstallationSettings) {
+			installationSettings.Order = i + 1
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save installation order: %w", err)
+		}
+	}
+	f.EmitGlobals()
+	return nil
+}
+
+func (f *ficsitCLI) updateInstallationSettings(path string, update func(*settings.InstallationSettings)) error {
+	if f.GetInstallation(path) == nil {
+		return fmt.Errorf("installation %s not found", path)
+	}
+	err := settings.UpdateInstallationSettings(path, update)
+	if err != nil {
+		return fmt.Errorf("failed to save installation settings: %w", err)
+	}
+	f.EmitGlobals()
+	return nil
+}
+
+// getAllInstallations returns all installations in the installations list, regardless of whether they are valid
+func (f *ficsitCLI) getAllInstallations() []string {
+	snapshot := f.getInstallationsSnapshot()
+	installations := make([]string, 0, len(snapshot))
+	for _, installation := range snapshot {
+		installations = append(installations, installation.Path)
+	}
+	return installations
+}
+
+// sortInstallations sorts the installations by their user set order. Installations without one keep their order, after the others.
+func (f *ficsitCLI) sortInstallations(installations []string) []string {
+	orders := make(map[string]int, len(installations))
+	for _, path := range installations {
+		orders[path] = settings.GetInstallationSettings(path).Order
+	}
+	slices.SortStableFunc(installations, func(a, b string) int {
+		orderA, orderB := orders[a], orders[b]
+		switch {
+		case orderA == orderB:
+			return 0
+		case orderA == 0:
+			return 1
+		case orderB == 0:
+			return -1
+		default:
+			return orderA - orderB
+		}
+	})
+	return installations
+}
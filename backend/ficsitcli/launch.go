@@ -0,0 +1,491 @@
+package ficsitcli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/exp/maps"
+
+	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/gameprocess"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/steam"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
+)
+
+type LaunchErrorReason string
+
+var (
+	LaunchErrorNoInstallation LaunchErrorReason = "noInstallation"
+	// LaunchErrorNoLaunchCommand is used when SMM does not know how to launch the installation,
+	// such as when legendary is not in PATH, or for remote servers
+	LaunchErrorNoLaunchCommand LaunchErrorReason = "noLaunchCommand"
+	LaunchErrorStartFailed     LaunchErrorReason = "startFailed"
+	// LaunchErrorExited is used when the launch command started, but exited with an error
+	LaunchErrorExited LaunchErrorReason = "exited"
+	// LaunchErrorIncompatibleMods is used when the game was updated and mods became incompatible,
+	// until the change is fixed or dismissed, see GetGameVersionChanges
+	LaunchErrorIncompatibleMods LaunchErrorReason = "incompatibleMods"
+)
+
+// LaunchError is returned when the game can't be launched,
+// or sent with the launchFailed event if the launch command fails after it started
+type LaunchError struct {
+	Path    string            `json:"path"`
+	Reason  LaunchErrorReason `json:"reason"`
+	Message string            `json:"message"`
+	Command []string          `json:"command,omitempty"`
+	// Output is the end of the output of the launch command, if it exited with an error
+	Output   string `json:"output,omitempty"`
+	ExitCode int    `json:"exitCode,omitempty"`
+}
+
+func (e *LaunchError) Error() string {
+	return e.Message
+}
+
+// maxLaunchOutput is how much of the output of the launch command is kept, since it may be the game itself, which runs for hours
+const maxLaunchOutput = 64 * 1024
+
+// tailWriter keeps the last max bytes written to it
+type tailWriter struct {
+	max  int
+	buf  []byte
+	lock sync.Mutex
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.max {
+		w.buf = slices.Clone(w.buf[len(w.buf)-w.max:])
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return string(w.buf)
+}
+
+// LaunchGame starts the game of the selected installation, without waiting for it to exit.
+// Errors after the launch command started are sent with the launchFailed event.
+func (f *ficsitCLI) LaunchGame() error {
+	selectedInstallation := f.GetSelectedInstall()
+	if selectedInstallation == nil {
+		return &LaunchError{
+			Reason:  LaunchErrorNoInstallation,
+			Message: "no installation selected",
+		}
+	}
+	if change, ok := f.getIncompatibleModsChange(selectedInstallation.Path); ok {
+		return &LaunchError{
+			Path:    selectedInstallation.Path,
+			Reason:  LaunchErrorIncompatibleMods,
+			Message: fmt.Sprintf("the game was updated, and %d mods are not compatible with it anymore. Update or disable them, or dismiss the game update, before launching", len(change.IncompatibleMods)),
+		}
+	}
+	return f.launchInstallation(selectedInstallation.Path)
+}
+
+func (f *ficsitCLI) launchInstallation(path string) error {
+	l := slog.With(slog.String("task", "launchGame"), slog.String("path", path))
+
+	metadata, ok := f.installationMetadata.Load(path)
+	if !ok || metadata.Info == nil {
+		l.Error("no metadata for installation")
+		return &LaunchError{
+			Path:    path,
+			Reason:  LaunchErrorNoInstallation,
+			Message: "no metadata for installation",
+		}
+	}
+	if len(metadata.Info.LaunchPath) == 0 {
+		l.Error("installation has no launch command")
+		return &LaunchError{
+			Path:    path,
+			Reason:  LaunchErrorNoLaunchCommand,
+			Message: fmt.Sprintf("%s can't be launched by SMM, launch it from %s instead", f.GetInstallationLabel(path).Name, metadata.Info.Launcher),
+		}
+	}
+
+	options := settings.GetInstallationSettings(path).LaunchOptions
+	if options != nil {
+		if err := checkLaunchOptions(metadata.Info, *options); err != nil {
+			// The options were set for a launch command the installation does not have anymore
+			l.Warn("launching without launch options", slog.Any("error", err))
+			options = nil
+		}
+	}
+	command, env := launchCommand(metadata.Info, options)
+
+	cmd := exec.Command(command[0], command[1:]...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	output := &tailWriter{max: maxLaunchOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+
+	err := cmd.Start()
+	if err != nil {
+		l.Error("failed to launch game", slog.Any("error", err), slog.String("cmd", cmd.String()))
+		return &LaunchError{
+			Path:    path,
+			Reason:  LaunchErrorStartFailed,
+			Message: fmt.Sprintf("failed to launch game: %s", err.Error()),
+			Command: command,
+		}
+	}
+	l.Info("launched game", slog.String("cmd", cmd.String()))
+
+	go func() {
+		err := cmd.Wait()
+		if err == nil {
+			return
+		}
+		launchErr := &LaunchError{
+			Path:    path,
+			Reason:  LaunchErrorExited,
+			Message: fmt.Sprintf("launch command failed: %s", err.Error()),
+			Command: command,
+			Output:  output.String(),
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			launchErr.ExitCode = exitErr.ExitCode()
+		}
+		l.Error("launch command failed", slog.Any("error", err), slog.String("cmd", cmd.String()), slog.String("output", launchErr.Output))
+		if appCommon.AppContext != nil {
+			wailsRuntime.EventsEmit(appCommon.AppContext, "launchFailed", launchErr)
+		}
+	}()
+
+	return nil
+}
+
+// launchCommand applies the launch options to the launch command of an installation,
+// returning the command to run, and the environment variables to add
+func launchCommand(installation *common.Installation, options *settings.LaunchOptions) ([]string, []string) {
+	command := slices.Clone(installation.LaunchPath)
+	if options == nil {
+		return command, nil
+	}
+	if installation.LaunchType == common.LaunchTypeSteamURL {
+		// Steam starts the game, so the arguments are passed to it in the URL, see checkLaunchOptions
+		if len(options.Args) > 0 {
+			for i, arg := range command {
+				command[i] = strings.ReplaceAll(arg, steam.LaunchURL, steam.LaunchURLWithArgs(options.Args))
+			}
+		}
+		return command, nil
+	}
+	command = append(command, options.Args...)
+	command = append(slices.Clone(options.Wrapper), command...)
+
+	keys := maps.Keys(options.Env)
+	slices.Sort(keys)
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+options.Env[key])
+	}
+	return command, env
+}
+
+// checkLaunchOptions rejects the launch options that would not reach the game, because its launch command asks another program to start it
+func checkLaunchOptions(installation *common.Installation, options settings.LaunchOptions) error {
+	switch installation.LaunchType {
+	case common.LaunchTypeHandoff:
+		if !options.IsEmpty() {
+			return fmt.Errorf("%s starts the game itself, so launch options can't be applied to it", installation.Launcher)
+		}
+	case common.LaunchTypeSteamURL:
+		if len(options.Env) > 0 || len(options.Wrapper) > 0 {
+			return fmt.Errorf("%s starts the game itself, so only arguments can be passed to it. Set environment variables and wrapper commands in the Steam launch options of the game instead", installation.Launcher)
+		}
+		for _, arg := range options.Args {
+			if arg == "" || strings.ContainsFunc(arg, unicode.IsSpace) {
+				return fmt.Errorf("%s can't pass arguments that are empty or contain spaces: %q", installation.Launcher, arg)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *ficsitCLI) GetLaunchOptions(path string) settings.LaunchOptions {
+	options := settings.GetInstallationSettings(path).LaunchOptions
+	if options == nil {
+		return settings.LaunchOptions{}
+	}
+	return *options
+}
+
+// SetLaunchOptions sets the extra arguments, environment variables and wrapper command the installation is launched with.
+// Empty options launch it with the launch command of its launcher as is.
+func (f *ficsitCLI) SetLaunchOptions(path string, options settings.LaunchOptions) error {
+	for key := range options.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return fmt.Errorf("invalid environment variable name %q", key)
+		}
+	}
+	if len(options.Wrapper) > 0 && strings.TrimSpace(options.Wrapper[0]) == "" {
+		return fmt.Errorf("the wrapper command must start with a program")
+	}
+	if metadata, ok := f.installationMetadata.Load(path); ok && metadata.Info != nil {
+		if err := checkLaunchOptions(metadata.Info, options); err != nil {
+			return err
+		}
+	}
+
+	return f.updateInstallationSettings(path, func(installationSettings *settings.InstallationSettings) {
+		if options.IsEmpty() {
+			installationSettings.LaunchOptions = nil
+			return
+		}
+		installationSettings.LaunchOptions = &options
+	})
+}
+
+// sessionProfileStartTimeout is how long LaunchWithProfile waits for the game to start before it gives up on restoring the profile.
+// Launchers may update the game before starting it, so this is generous.
+const sessionProfileStartTimeout = 10 * time.Minute
+
+// sessionProfileRestore is the profile switch LaunchWithProfile undoes once the game exits
+type sessionProfileRestore struct {
+	// previous is the profile of the installation before the first session
+	previous string
+	// session is the profile of the last session, which is only undone if the installation still uses it
+	session string
+}
+
+// ProfileRestoreError is sent in the profileRestoreFailed event when LaunchWithProfile can't switch the installation back to its previous profile
+type ProfileRestoreError struct {
+	Path            string `json:"path"`
+	PreviousProfile string `json:"previousProfile"`
+	Message         string `json:"message"`
+}
+
+// LaunchWithProfile switches the installation to the profile, installs its mods, then launches the game.
+// If restoreAfterExit is set, the installation is switched back to its previous profile once the game exits.
+// The previous profile is not restored if SMM is closed before the game exits.
+// If it can't be restored, such as when SMM can't tell which installation the game was started from, the profileRestoreFailed event is sent.
+func (f *ficsitCLI) LaunchWithProfile(path string, profile string, restoreAfterExit bool) error {
+	installation := f.GetInstallation(path)
+	if installation == nil || !f.isValidInstall(path) {
+		return fmt.Errorf("invalid installation: %s", path)
+	}
+	if f.GetProfile(profile) == nil {
+		return fmt.Errorf("profile %s not found", profile)
+	}
+	if f.IsInstallationRunning(path) {
+		// The profile switch would wait for the game to exit, or fail
+		return fmt.Errorf("the game is already running")
+	}
+	if restoreAfterExit && !gameprocess.CanMatchInstallations() {
+		return fmt.Errorf("SMM can't tell when the game exits on this platform, so it can't restore the profile afterwards")
+	}
+	l := slog.With(slog.String("task", "launchWithProfile"), slog.String("path", path), slog.String("profile", profile))
+
+	previousProfile := f.getInstallationProfile(installation)
+	if restoreAfterExit {
+		// If the game is launched again before the first session ends, the profile from before the first session is the one to restore
+		if pending := f.getSessionProfileRestore(path); pending != nil {
+			previousProfile = pending.previous
+		}
+	}
+
+	err := f.setProfile(installation, profile)
+	if err != nil {
+		l.Error("failed to switch profile", slog.Any("error", err))
+		return err
+	}
+
+	err = f.launchInstallation(path)
+	if err != nil {
+		if restoreAfterExit && previousProfile != profile {
+			if restoreErr := f.setProfile(installation, previousProfile); restoreErr != nil {
+				l.Error("failed to restore profile", slog.Any("error", restoreErr))
+			}
+		}
+		return err
+	}
+
+	f.sessionProfileRestoresLock.Lock()
+	defer f.sessionProfileRestoresLock.Unlock()
+	if !restoreAfterExit || previousProfile == profile {
+		// The goroutine of a pending restore finds it removed, and stops
+		delete(f.sessionProfileRestores, path)
+		return nil
+	}
+	// A pending restore is replaced with the new session, and its goroutine handles that one once the game exits
+	_, pending := f.sessionProfileRestores[path]
+	f.sessionProfileRestores[path] = &sessionProfileRestore{
+		previous: previousProfile,
+		session:  profile,
+	}
+	if !pending {
+		go f.restoreProfileAfterExit(installation)
+	}
+	return nil
+}
+
+func (f *ficsitCLI) getSessionProfileRestore(path string) *sessionProfileRestore {
+	f.sessionProfileRestoresLock.Lock()
+	defer f.sessionProfileRestoresLock.Unlock()
+	return f.sessionProfileRestores[path]
+}
+
+// restoreProfileAfterExit waits for the game of the installation to start and exit,
+// then switches it back to the profile it had before LaunchWithProfile.
+// It owns the restore of the installation until it removes it, so if the game is launched again meanwhile, it handles the new session too.
+func (f *ficsitCLI) restoreProfileAfterExit(installation *cli.Installation) {
+	for {
+		handled := f.restoreSessionProfile(installation)
+		if f.finishSessionProfileRestore(installation.Path, handled) {
+			return
+		}
+	}
+}
+
+// finishSessionProfileRestore removes the restore of the installation and returns true, unless it was replaced by a session started since
+func (f *ficsitCLI) finishSessionProfileRestore(path string, handled *sessionProfileRestore) bool {
+	f.sessionProfileRestoresLock.Lock()
+	defer f.sessionProfileRestoresLock.Unlock()
+	if current, ok := f.sessionProfileRestores[path]; ok && current != handled {
+		return false
+	}
+	delete(f.sessionProfileRestores, path)
+	return true
+}
+
+// restoreSessionProfile waits for one session of the game of the installation, then switches back the profile.
+// It returns the restore it handled, which is nil if it was removed meanwhile.
+func (f *ficsitCLI) restoreSessionProfile(installation *cli.Installation) *sessionProfileRestore {
+	l := slog.With(slog.String("task", "restoreProfileAfterExit"), slog.String("path", installation.Path))
+
+	if start := f.waitForSessionStart(installation.Path); start != sessionStarted {
+		restore := f.getSessionProfileRestore(installation.Path)
+		if restore == nil {
+			return nil
+		}
+		switch start {
+		case sessionUnmatched:
+			l.Warn("game started, but not from a known installation, not restoring profile")
+			f.emitProfileRestoreFailed(installation.Path, restore, "SMM can't tell which installation the game was started from, so it can't restore the profile once the game exits")
+		case sessionTimedOut:
+			l.Warn("game did not start, not restoring profile")
+			f.emitProfileRestoreFailed(installation.Path, restore, fmt.Sprintf("the game did not start within %s, so the profile was not restored", sessionProfileStartTimeout))
+		}
+		return restore
+	}
+	f.waitForInstallationRunning(installation.Path, false, 0)
+
+	restore := f.getSessionProfileRestore(installation.Path)
+	if restore == nil {
+		return nil
+	}
+	if profile := f.getInstallationProfile(installation); profile != restore.session {
+		l.Info("profile was changed during the session, not restoring it", slog.String("profile", profile))
+		return restore
+	}
+
+	l.Info("game exited, restoring profile", slog.String("profile", restore.previous))
+	err := f.setProfile(installation, restore.previous)
+	if errors.Is(err, ErrActionQueued) {
+		// Another game was started from the installation, the queued restore reports its own failure
+		l.Info("game is running again, restoring profile once it exits")
+		return restore
+	}
+	if err != nil {
+		l.Error("failed to restore profile", slog.Any("error", err))
+		f.emitProfileRestoreFailed(installation.Path, restore, fmt.Sprintf("failed to restore profile: %s", err.Error()))
+	}
+	return restore
+}
+
+func (f *ficsitCLI) emitProfileRestoreFailed(path string, restore *sessionProfileRestore, message string) {
+	if appCommon.AppContext != nil {
+		wailsRuntime.EventsEmit(appCommon.AppContext, "profileRestoreFailed", ProfileRestoreError{
+			Path:            path,
+			PreviousProfile: restore.previous,
+			Message:         message,
+		})
+	}
+}
+
+type sessionStart int
+
+const (
+	sessionStarted sessionStart = iota
+	// sessionUnmatched is when a game started, but the process watcher could not match it to any installation
+	sessionUnmatched
+	sessionTimedOut
+)
+
+// waitForSessionStart waits for the game of the installation to start, as reported by the process watcher.
+// A game that can't be matched to an installation is given a few polls to be matched, in case the installations were being updated.
+func (f *ficsitCLI) waitForSessionStart(path string) sessionStart {
+	deadline := time.Now().Add(sessionProfileStartTimeout)
+	var unmatchedSince time.Time
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		if f.IsInstallationRunning(path) {
+			return sessionStarted
+		}
+		if !f.isUnmatchedGameRunning() {
+			unmatchedSince = time.Time{}
+		} else if unmatchedSince.IsZero() {
+			unmatchedSince = time.Now()
+		} else if time.Since(unmatchedSince) > 2*gameProcessPollInterval {
+			return sessionUnmatched
+		}
+		if time.Now().After(deadline) {
+			return sessionTimedOut
+		}
+		<-ticker.C
+	}
+}
+
+// waitForInstallationRunning waits until the game of the installation is running or not, as reported by the process watcher.
+// It returns false if that did not happen before the timeout. A timeout of 0 waits forever.
+func (f *ficsitCLI) waitForInstallationRunning(path string, running bool, timeout time.Duration) bool {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		if f.IsInstallationRunning(path) == running {
+			return true
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return false
+		}
+		<-ticker.C
+	}
+}
+
+var AllLaunchErrorReasons = []struct {
+	Value  LaunchErrorReason
+	TSName string
+}{
+	{LaunchErrorNoInstallation, "NO_INSTALLATION"},
+	{LaunchErrorNoLaunchCommand, "NO_LAUNCH_COMMAND"},
+	{LaunchErrorStartFailed, "START_FAILED"},
+	{LaunchErrorExited, "EXITED"},
+	{LaunchErrorIncompatibleMods, "INCOMPATIBLE_MODS"},
+}
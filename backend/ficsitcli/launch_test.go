@@ -0,0 +1,154 @@
+package ficsitcli
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
+)
+
+func TestLaunchCommand(t *testing.T) {
+	options := &settings.LaunchOptions{
+		Args:    []string{"-log", "-NoSteamClient"},
+		Env:     map[string]string{"B": "2", "A": "1"},
+		Wrapper: []string{"gamemoderun"},
+	}
+	tests := []struct {
+		name            string
+		installation    common.Installation
+		options         *settings.LaunchOptions
+		expectedCommand []string
+		expectedEnv     []string
+	}{
+		{
+			name:            "no options",
+			installation:    common.Installation{LaunchPath: []string{"/games/Satisfactory/FactoryServer.sh"}},
+			expectedCommand: []string{"/games/Satisfactory/FactoryServer.sh"},
+		},
+		{
+			name:            "direct",
+			installation:    common.Installation{LaunchPath: []string{"/games/Satisfactory/FactoryServer.sh"}},
+			options:         options,
+			expectedCommand: []string{"gamemoderun", "/games/Satisfactory/FactoryServer.sh", "-log", "-NoSteamClient"},
+			expectedEnv:     []string{"A=1", "B=2"},
+		},
+		{
+			name: "steam",
+			installation: common.Installation{
+				LaunchPath: []string{"cmd", "/C", "start", "", "steam://rungameid/526870"},
+				LaunchType: common.LaunchTypeSteamURL,
+			},
+			options:         &settings.LaunchOptions{Args: options.Args},
+			expectedCommand: []string{"cmd", "/C", "start", "", "steam://run/526870//-log%20-NoSteamClient/"},
+		},
+		{
+			name: "steam without arguments",
+			installation: common.Installation{
+				LaunchPath: []string{"steam", "steam://rungameid/526870"},
+				LaunchType: common.LaunchTypeSteamURL,
+			},
+			options:         &settings.LaunchOptions{},
+			expectedCommand: []string{"steam", "steam://rungameid/526870"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command, env := launchCommand(&test.installation, test.options)
+			if !slices.Equal(command, test.expectedCommand) {
+				t.Errorf("command = %q, expected %q", command, test.expectedCommand)
+			}
+			if !slices.Equal(env, test.expectedEnv) {
+				t.Errorf("env = %q, expected %q", env, test.expectedEnv)
+			}
+		})
+	}
+}
+
+func TestCheckLaunchOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		launchType common.LaunchType
+		options    settings.LaunchOptions
+		valid      bool
+	}{
+		{
+			name:    "direct",
+			options: settings.LaunchOptions{Args: []string{"-log"}, Env: map[string]string{"A": "1"}, Wrapper: []string{"mangohud"}},
+			valid:   true,
+		},
+		{
+			name:       "handoff without options",
+			launchType: common.LaunchTypeHandoff,
+			valid:      true,
+		},
+		{
+			name:       "handoff",
+			launchType: common.LaunchTypeHandoff,
+			options:    settings.LaunchOptions{Args: []string{"-log"}},
+		},
+		{
+			name:       "steam arguments",
+			launchType: common.LaunchTypeSteamURL,
+			options:    settings.LaunchOptions{Args: []string{"-log", "-ini:Engine:[Core.Log]:LogNet=Verbose"}},
+			valid:      true,
+		},
+		{
+			name:       "steam argument with a space",
+			launchType: common.LaunchTypeSteamURL,
+			options:    settings.LaunchOptions{Args: []string{"-savedir=My Saves"}},
+		},
+		{
+			name:       "steam environment",
+			launchType: common.LaunchTypeSteamURL,
+			options:    settings.LaunchOptions{Env: map[string]string{"A": "1"}},
+		},
+		{
+			name:       "steam wrapper",
+			launchType: common.LaunchTypeSteamURL,
+			options:    settings.LaunchOptions{Wrapper: []string{"gamemoderun"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkLaunchOptions(&common.Installation{Launcher: "Launcher", LaunchType: test.launchType}, test.options)
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("expected the options to be rejected")
+			}
+		})
+	}
+}
+
+func TestFinishSessionProfileRestore(t *testing.T) {
+	handled := &sessionProfileRestore{previous: "Default", session: "Modded"}
+	relaunched := &sessionProfileRestore{previous: "Default", session: "Modded"}
+	tests := []struct {
+		name     string
+		current  *sessionProfileRestore
+		finished bool
+	}{
+		{"handled", handled, true},
+		{"removed", nil, true},
+		// The game was launched again with the same profiles while the handled session was restored
+		{"relaunched", relaunched, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := &ficsitCLI{sessionProfileRestores: make(map[string]*sessionProfileRestore)}
+			if test.current != nil {
+				f.sessionProfileRestores["game"] = test.current
+			}
+			if finished := f.finishSessionProfileRestore("game", handled); finished != test.finished {
+				t.Errorf("finishSessionProfileRestore() = %t, expected %t", finished, test.finished)
+			}
+			if current := f.getSessionProfileRestore("game"); test.finished && current != nil {
+				t.Errorf("expected the finished restore to be removed")
+			} else if !test.finished && current != test.current {
+				t.Errorf("expected the new session's restore to be kept")
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package ficsitcli
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
+)
+
+// findManualInstallations validates the installations added by the user, skipping the ones a launcher already found
+func findManualInstallations(found []*common.Installation) ([]*common.Installation, []error) {
+	installs := make([]*common.Installation, 0)
+	var findErrors []error
+	for _, manual := range settings.GetManualInstallations() {
+		alreadyFound := slices.ContainsFunc(found, func(install *common.Installation) bool {
+			return common.OsPathEqual(install.Path, manual.Path)
+		})
+		if alreadyFound {
+			continue
+		}
+		install, err := installfinders.ManualInstallation(manual.Path, manual.LaunchCommand)
+		if err != nil {
+			findErrors = append(findErrors, err)
+			continue
+		}
+		// Keep the path the user added, so it matches the one in installations.json
+		install.Path = manual.Path
+		installs = append(installs, install)
+	}
+	return installs, findErrors
+}
+
+// existingInstallationPath returns the path of the installation that is the same as path, which may differ in case on Windows
+func (f *ficsitCLI) existingInstallationPath(path string) (string, bool) {
+	for _, installation := range f.getInstallationsSnapshot() {
+		if common.OsPathEqual(installation.Path, path) {
+			return installation.Path, true
+		}
+	}
+	return "", false
+}
+
+// AddLocalInstallation adds a game installation that no launcher finds, such as a copy of the game in a custom folder.
+// If launchCommand is empty, the game executable is launched directly.
+func (f *ficsitCLI) AddLocalInstallation(path string, launchCommand []string) error {
+	install, err := installfinders.ManualInstallation(path, launchCommand)
+	if err != nil {
+		return fmt.Errorf("invalid installation: %w", err)
+	}
+	l := slog.With(slog.String("task", "addLocalInstallation"), slog.String("path", install.Path))
+
+	existingPath, exists := f.existingInstallationPath(install.Path)
+	if exists {
+		if f.isValidInstall(existingPath) {
+			return fmt.Errorf("installation already exists")
+		}
+		// The installation was known before, but is not found anymore, so keep using its path
+		install.Path = existingPath
+	}
+
+	err = settings.SetManualInstallation(settings.ManualInstallation{
+		Path:          install.Path,
+		LaunchCommand: launchCommand,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save installation: %w", err)
+	}
+
+	if !exists {
+		f.updateInstallations(l, func() {
+			_, err = f.ficsitCli.Installations.AddInstallation(f.ficsitCli, install.Path, f.getFallbackProfile())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add installation: %w", err)
+		}
+	}
+
+	f.installationMetadata.Store(install.Path, installationMetadata{
+		State: InstallStateValid,
+		Info:  install,
+	})
+
+	f.EmitGlobals()
+	return nil
+}
+
+// RemoveLocalInstallation removes an installation added with AddLocalInstallation
+func (f *ficsitCLI) RemoveLocalInstallation(path string) error {
+	metadata, ok := f.installationMetadata.Load(path)
+	if !ok {
+		return fmt.Errorf("installation not found")
+	}
+	isManual := slices.ContainsFunc(settings.GetManualInstallations(), func(manual settings.ManualInstallation) bool {
+		return manual.Path == path
+	})
+	if !isManual {
+		return fmt.Errorf("installation was not added manually")
+	}
+
+	lock := f.installLock(path)
+	if !lock.TryLock() {
+		return fmt.Errorf("another operation in progress")
+	}
+	defer lock.Unlock()
+
+	err := settings.DeleteManualInstallation(path)
+	if err != nil {
+		return fmt.Errorf("failed to save installations: %w", err)
+	}
+
+	if metadata.Info != nil && metadata.Info.Launcher != installfinders.ManualLauncher {
+		// A launcher also finds the installation, so it stays
+		return nil
+	}
+
+	f.updateInstallations(slog.Default(), func() {
+		err = f.ficsitCli.Installations.DeleteInstallation(path)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete installation: %w", err)
+	}
+	f.installationMetadata.Delete(path)
+	f.installLocks.Delete(path)
+	err = settings.DeleteInstallationSettings(path)
+	if err != nil {
+		slog.Error("failed to delete installation settings", slog.Any("error", err))
+	}
+
+	f.ensureSelectedInstallationIsValid()
+	f.EmitGlobals()
+	return nil
+}
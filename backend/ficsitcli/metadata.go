@@ -1,30 +1,47 @@
 package ficsitcli
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"path"
 	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/andygrunwald/vdf"
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
 )
 
-func (f *ficsitCLI) initLocalInstallationsMetadata() error {
+// findLocalInstallations finds the installations of all launchers, and the ones added manually
+func findLocalInstallations() ([]*common.Installation, []error) {
 	installs, findErrors := installfinders.FindInstallations()
 
-	f.installFindErrors = findErrors
+	manualInstalls, manualErrors := findManualInstallations(installs)
+	installs = append(installs, manualInstalls...)
+	findErrors = append(findErrors, manualErrors...)
+
+	return installs, findErrors
+}
+
+func (f *ficsitCLI) initLocalInstallationsMetadata() error {
+	installs, findErrors := findLocalInstallations()
+
+	f.setInstallFindErrors(findErrors)
 
 	fallbackProfile := f.GetFallbackProfile()
 
 	createdNewInstalls := false
 	for _, install := range installs {
-		ficsitCliInstall := f.ficsitCli.Installations.GetInstallation(install.Path)
-		if ficsitCliInstall == nil {
+		if existingPath, ok := f.existingInstallationPath(install.Path); ok {
+			// A manually added installation may have been stored with a different case than the one found by a launcher
+			install.Path = existingPath
+		} else {
 			_, err := f.ficsitCli.Installations.AddInstallation(f.ficsitCli, install.Path, fallbackProfile)
 			if err != nil {
 				return fmt.Errorf("failed to add installation: %w", err)
@@ -47,8 +64,9 @@ func (f *ficsitCLI) initLocalInstallationsMetadata() error {
 }
 
 func (f *ficsitCLI) initRemoteServerInstallationsMetadata() {
-	installationsToCheck := make([]*cli.Installation, 0, len(f.ficsitCli.Installations.Installations))
-	for _, installation := range f.ficsitCli.Installations.Installations {
+	installations := f.getInstallationsSnapshot()
+	installationsToCheck := make([]*cli.Installation, 0, len(installations))
+	for _, installation := range installations {
 		if meta, ok := f.installationMetadata.Load(installation.Path); ok {
 			if meta.State != InstallStateUnknown {
 				// Already have metadata for this install
@@ -82,7 +100,7 @@ func (f *ficsitCLI) initRemoteServerInstallationsMetadata() {
 	}
 	wg.Wait()
 
-	meta, ok := f.installationMetadata.Load(f.ficsitCli.Installations.SelectedInstallation)
+	meta, ok := f.installationMetadata.Load(f.getSelectedInstallationPath())
 	if !ok || meta.State == InstallStateInvalid {
 		f.ensureSelectedInstallationIsValid()
 	}
@@ -114,10 +132,11 @@ func (f *ficsitCLI) fetchRemoteInstallationMetadata(installation *cli.Installati
 		State: InstallStateValid,
 		Info:  meta,
 	})
+	f.checkGameVersion(installation.Path, meta.Version)
 }
 
 func (f *ficsitCLI) FetchRemoteServerMetadata(path string) error {
-	installation := f.ficsitCli.Installations.GetInstallation(path)
+	installation := f.GetInstallation(path)
 	if installation == nil {
 		return fmt.Errorf("installation not found")
 	}
@@ -158,23 +177,133 @@ func (f *ficsitCLI) getRemoteServerMetadata(installation *cli.Installation) (*co
 		return nil, ErrInstallNotServer
 	}
 
-	branch := common.BranchEarlyAccess // TODO: Do we have a way to detect this for remote installs?
+	remoteGameVersion := readRemoteGameVersion(installation)
+	branch, branchKey := detectRemoteBranch(installation, remoteGameVersion)
 
 	return &common.Installation{
-		Path:     installation.Path,
-		Type:     installType,
-		Location: common.LocationTypeRemote,
-		Branch:   branch,
-		Version:  gameVersion,
-		Launcher: f.getNextRemoteLauncherName(),
+		Path:        installation.Path,
+		Type:        installType,
+		Location:    common.LocationTypeRemote,
+		Branch:      branch,
+		BranchKey:   branchKey,
+		Version:     gameVersion,
+		GameVersion: remoteGameVersion,
+		Launcher:    f.getNextRemoteLauncherName(),
 	}, nil
 }
 
+// Dedicated servers installed by steam or steamcmd have their app manifest two directories up
+var remoteSteamManifests = []string{"appmanifest_1690800.acf"}
+
+// readRemoteGameVersion reads the first game version file the server has, returning nil if there is none that can be read
+func readRemoteGameVersion(installation *cli.Installation) *common.GameVersion {
+	l := slog.With(slog.String("task", "readRemoteGameVersion"), slog.String("path", installation.Path))
+
+	d, err := installation.GetDisk()
+	if err != nil {
+		l.Warn("failed to get disk", slog.Any("error", err))
+		return nil
+	}
+	basePath := installation.BasePath()
+
+	for _, versionFilePath := range common.ServerVersionFilePaths() {
+		fullPath := path.Join(basePath, versionFilePath)
+		exists, err := d.Exists(fullPath)
+		if err != nil || !exists {
+			continue
+		}
+		data, err := d.Read(fullPath)
+		if err != nil {
+			l.Warn("failed to read version file", slog.Any("error", err), slog.String("file", versionFilePath))
+			continue
+		}
+		var versionData common.GameVersionFile
+		if err := json.Unmarshal(data, &versionData); err != nil {
+			l.Warn("failed to parse version file", slog.Any("error", err), slog.String("file", versionFilePath))
+			continue
+		}
+		gameVersion := versionData.GameVersion()
+		return &gameVersion
+	}
+	return nil
+}
+
+// detectRemoteBranch reads the branch from the steam app manifest next to the installation, falling back to the game version.
+// The manifest is checked first, since the version file does not tell all branches apart.
+// It also returns what the branch was detected from, which is kept even if the branch is unknown.
+func detectRemoteBranch(installation *cli.Installation, gameVersion *common.GameVersion) (common.GameBranch, string) {
+	l := slog.With(slog.String("task", "detectRemoteBranch"), slog.String("path", installation.Path))
+
+	betakey, hasManifest := readRemoteSteamBetaKey(l, installation)
+	if hasManifest {
+		if branch, ok := common.BranchFromSteamBetaKey(betakey); ok {
+			return branch, betakey
+		}
+		l.Debug("unknown steam beta key", slog.String("betakey", betakey))
+	}
+
+	if gameVersion != nil {
+		if branch, ok := common.BranchFromVersionFile(gameVersion.BranchName); ok {
+			return branch, gameVersion.BranchName
+		}
+		l.Debug("unknown version file branch name", slog.String("branchName", gameVersion.BranchName))
+	}
+
+	if hasManifest {
+		return common.BranchUnknown, betakey
+	}
+	if gameVersion != nil {
+		return common.BranchUnknown, gameVersion.BranchName
+	}
+	return common.BranchUnknown, ""
+}
+
+// readRemoteSteamBetaKey reads the steam beta key from the app manifest next to the installation, if there is one
+func readRemoteSteamBetaKey(l *slog.Logger, installation *cli.Installation) (string, bool) {
+	d, err := installation.GetDisk()
+	if err != nil {
+		l.Warn("failed to get disk", slog.Any("error", err))
+		return "", false
+	}
+	basePath := installation.BasePath()
+
+	// <library>/steamapps/common/<installdir>
+	if path.Base(path.Dir(basePath)) != "common" {
+		return "", false
+	}
+	steamAppsPath := path.Dir(path.Dir(basePath))
+	for _, manifestName := range remoteSteamManifests {
+		manifestPath := path.Join(steamAppsPath, manifestName)
+		exists, err := d.Exists(manifestPath)
+		if err != nil || !exists {
+			continue
+		}
+		data, err := d.Read(manifestPath)
+		if err != nil {
+			l.Warn("failed to read steam manifest", slog.Any("error", err))
+			continue
+		}
+		manifest, err := vdf.NewParser(bytes.NewReader(data)).Parse()
+		if err != nil {
+			l.Warn("failed to parse steam manifest", slog.Any("error", err))
+			continue
+		}
+		appState, _ := manifest["AppState"].(map[string]interface{})
+		if installDir, _ := appState["installdir"].(string); installDir != path.Base(basePath) {
+			continue
+		}
+		userConfig, _ := appState["UserConfig"].(map[string]interface{})
+		betakey, _ := userConfig["betakey"].(string)
+		return betakey, true
+	}
+	return "", false
+}
+
 func (f *ficsitCLI) getNextRemoteLauncherName() string {
 	existingNumbers := make(map[int]bool)
-	for _, install := range f.GetRemoteInstallations() {
-		metadata, ok := f.installationMetadata.Load(install)
-		if ok && metadata.Info != nil {
+	// Hidden installations still hold on to their name
+	f.installationMetadata.Range(func(_ string, metadata installationMetadata) bool {
+		if metadata.Info != nil && metadata.Info.Location == common.LocationTypeRemote {
 			if strings.HasPrefix(metadata.Info.Launcher, "Remote ") {
 				num, err := strconv.Atoi(strings.TrimPrefix(metadata.Info.Launcher, "Remote "))
 				if err == nil {
@@ -182,7 +311,8 @@ func (f *ficsitCLI) getNextRemoteLauncherName() string {
 				}
 			}
 		}
-	}
+		return true
+	})
 	for i := 1; ; i++ {
 		if !existingNumbers[i] {
 			return "Remote " + strconv.Itoa(i)
@@ -3,73 +3,55 @@ package ficsitcli
 import (
 	"fmt"
 	"log/slog"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
 )
 
 func (f *ficsitCLI) InstallMod(mod string) error {
-	return f.action(ActionInstall, newSimpleItem(mod), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
-		selectedInstallation := f.GetSelectedInstall()
-
-		if selectedInstallation == nil {
-			return fmt.Errorf("no installation selected")
-		}
-
-		l = l.With(
-			slog.String("install", selectedInstallation.Path),
-			slog.String("profile", selectedInstallation.Profile),
-		)
-
-		profileName := selectedInstallation.Profile
-		profile := f.GetProfile(profileName)
-
-		profileErr := profile.AddMod(mod, ">=0.0.0")
-		if profileErr != nil {
-			l.Error("failed to add mod", slog.Any("error", profileErr))
-			return fmt.Errorf("failed to add mod: %s@latest: %w", mod, profileErr)
-		}
+	selectedInstallation := f.GetSelectedInstall()
 
-		err := f.ficsitCli.Profiles.Save()
-		if err != nil {
-			l.Error("failed to save profile", slog.Any("error", err))
-		}
+	if selectedInstallation == nil {
+		return fmt.Errorf("no installation selected")
+	}
 
-		installErr := f.validateInstall(selectedInstallation, taskUpdates)
-
-		if installErr != nil {
-			l.Error("failed to install", slog.Any("error", installErr))
-			return installErr
-		}
-
-		return nil
-	})
+	return f.installMod(selectedInstallation, mod, "")
 }
 
 func (f *ficsitCLI) InstallModVersion(mod string, version string) error {
-	return f.action(ActionInstall, newItem(mod, version), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
-		selectedInstallation := f.GetSelectedInstall()
+	selectedInstallation := f.GetSelectedInstall()
 
-		if selectedInstallation == nil {
-			return fmt.Errorf("no installation selected")
-		}
+	if selectedInstallation == nil {
+		return fmt.Errorf("no installation selected")
+	}
 
-		l = l.With(
-			slog.String("install", selectedInstallation.Path),
-			slog.String("profile", selectedInstallation.Profile),
-		)
-
-		profile := f.GetProfile(selectedInstallation.Profile)
+	return f.installMod(selectedInstallation, mod, version)
+}
 
-		profileErr := profile.AddMod(mod, version)
+// installMod adds the mod to the profile of the installation, and installs it.
+// An empty version installs the latest one.
+func (f *ficsitCLI) installMod(installation *cli.Installation, mod string, version string) error {
+	item := newItem(mod, version)
+	versionConstraint := version
+	versionName := version
+	if version == "" {
+		item = newSimpleItem(mod)
+		versionConstraint = ">=0.0.0"
+		versionName = "latest"
+	}
+
+	return f.action(installation, ActionInstall, item, func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
+		profileName := f.getInstallationProfile(installation)
+		l = l.With(slog.String("profile", profileName))
+
+		profileErr := f.updateProfile(l, profileName, func(profile *cli.Profile) error {
+			return profile.AddMod(mod, versionConstraint) //nolint:wrapcheck
+		})
 		if profileErr != nil {
 			l.Error("failed to add mod", slog.Any("error", profileErr))
-			return fmt.Errorf("failed to add mod: %s@%s: %w", mod, version, profileErr)
-		}
-
-		err := f.ficsitCli.Profiles.Save()
-		if err != nil {
-			l.Error("failed to save profile", slog.Any("error", err))
+			return fmt.Errorf("failed to add mod: %s@%s: %w", mod, versionName, profileErr)
 		}
 
-		installErr := f.validateInstall(selectedInstallation, taskUpdates)
+		installErr := f.validateInstall(installation, taskUpdates)
 
 		if installErr != nil {
 			l.Error("failed to install", slog.Any("error", installErr))
@@ -81,28 +63,29 @@ func (f *ficsitCLI) InstallModVersion(mod string, version string) error {
 }
 
 func (f *ficsitCLI) RemoveMod(mod string) error {
-	return f.action(ActionUninstall, newSimpleItem(mod), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
-		selectedInstallation := f.GetSelectedInstall()
+	selectedInstallation := f.GetSelectedInstall()
 
-		if selectedInstallation == nil {
-			return fmt.Errorf("no installation selected")
-		}
-
-		l = l.With(
-			slog.String("install", selectedInstallation.Path),
-			slog.String("profile", selectedInstallation.Profile),
-		)
+	if selectedInstallation == nil {
+		return fmt.Errorf("no installation selected")
+	}
 
-		profile := f.GetProfile(selectedInstallation.Profile)
+	return f.removeMod(selectedInstallation, mod)
+}
 
-		profile.RemoveMod(mod)
+func (f *ficsitCLI) removeMod(installation *cli.Installation, mod string) error {
+	return f.action(installation, ActionUninstall, newSimpleItem(mod), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
+		profileName := f.getInstallationProfile(installation)
+		l = l.With(slog.String("profile", profileName))
 
-		err := f.ficsitCli.Profiles.Save()
+		err := f.updateProfile(l, profileName, func(profile *cli.Profile) error {
+			profile.RemoveMod(mod)
+			return nil
+		})
 		if err != nil {
-			l.Error("failed to save profile", slog.Any("error", err))
+			return err
 		}
 
-		installErr := f.validateInstall(selectedInstallation, taskUpdates)
+		installErr := f.validateInstall(installation, taskUpdates)
 
 		if installErr != nil {
 			l.Error("failed to install", slog.Any("error", installErr))
@@ -114,58 +97,30 @@ func (f *ficsitCLI) RemoveMod(mod string) error {
 }
 
 func (f *ficsitCLI) EnableMod(mod string) error {
-	return f.action(ActionEnable, newSimpleItem(mod), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
-		selectedInstallation := f.GetSelectedInstall()
-
-		if selectedInstallation == nil {
-			return fmt.Errorf("no installation selected")
-		}
-
-		l = l.With(
-			slog.String("install", selectedInstallation.Path),
-			slog.String("profile", selectedInstallation.Profile),
-		)
-
-		profile := f.GetProfile(selectedInstallation.Profile)
-
-		profile.SetModEnabled(mod, true)
-
-		err := f.ficsitCli.Profiles.Save()
-		if err != nil {
-			l.Error("failed to save profile", slog.Any("error", err))
-		}
-
-		installErr := f.validateInstall(selectedInstallation, taskUpdates)
-
-		if installErr != nil {
-			l.Error("failed to install", slog.Any("error", installErr))
-			return installErr
-		}
-
-		return nil
-	})
+	return f.setModEnabled(ActionEnable, mod, true)
 }
 
 func (f *ficsitCLI) DisableMod(mod string) error {
-	return f.action(ActionDisable, newSimpleItem(mod), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
-		selectedInstallation := f.GetSelectedInstall()
-
-		if selectedInstallation == nil {
-			return fmt.Errorf("no installation selected")
-		}
+	return f.setModEnabled(ActionDisable, mod, false)
+}
 
-		l = l.With(
-			slog.String("install", selectedInstallation.Path),
-			slog.String("profile", selectedInstallation.Profile),
-		)
+func (f *ficsitCLI) setModEnabled(action Action, mod string, enabled bool) error {
+	selectedInstallation := f.GetSelectedInstall()
 
-		profile := f.GetProfile(selectedInstallation.Profile)
+	if selectedInstallation == nil {
+		return fmt.Errorf("no installation selected")
+	}
 
-		profile.SetModEnabled(mod, false)
+	return f.action(selectedInstallation, action, newSimpleItem(mod), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
+		profileName := f.getInstallationProfile(selectedInstallation)
+		l = l.With(slog.String("profile", profileName))
 
-		err := f.ficsitCli.Profiles.Save()
+		err := f.updateProfile(l, profileName, func(profile *cli.Profile) error {
+			profile.SetModEnabled(mod, enabled)
+			return nil
+		})
 		if err != nil {
-			l.Error("failed to save profile", slog.Any("error", err))
+			return err
 		}
 
 		installErr := f.validateInstall(selectedInstallation, taskUpdates)
@@ -178,3 +133,24 @@ func (f *ficsitCLI) DisableMod(mod string) error {
 		return nil
 	})
 }
+
+// updateProfile applies change to the profile while holding profilesLock, then saves the profiles
+func (f *ficsitCLI) updateProfile(l *slog.Logger, name string, change func(*cli.Profile) error) error {
+	f.profilesLock.Lock()
+	defer f.profilesLock.Unlock()
+
+	profile := f.getProfile(name)
+	if profile == nil {
+		return fmt.Errorf("profile %s not found", name)
+	}
+
+	if err := change(profile); err != nil {
+		return err
+	}
+
+	err := f.ficsitCli.Profiles.Save()
+	if err != nil {
+		l.Error("failed to save profile", slog.Any("error", err))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package ficsitcli
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	"github.com/satisfactorymodding/ficsit-cli/utils"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
+)
+
+// installContext returns a ficsit-cli context for operations on the installation,
+// in which its profile has the installation's mod overrides applied
+func (f *ficsitCLI) installContext(installation *cli.Installation) *cli.GlobalContext {
+	ctx := f.contextSnapshot()
+	profile, ok := ctx.Profiles.Profiles[f.getInstallationProfile(installation)]
+	if !ok {
+		return ctx
+	}
+	applyModOverrides(profile, settings.GetInstallationSettings(installation.Path).ModOverrides)
+	return ctx
+}
+
+func applyModOverrides(profile *cli.Profile, overrides map[string]settings.ModOverride) {
+	for modReference, override := range overrides {
+		mod, inProfile := profile.Mods[modReference]
+		if !inProfile {
+			// Only forcing a mod on can add it to the installation
+			if override.Enabled == nil || !*override.Enabled {
+				continue
+			}
+			mod.Version = ">=0.0.0"
+		}
+		if override.Enabled != nil {
+			mod.Enabled = *override.Enabled
+		}
+		if override.Version != "" {
+			mod.Version = override.Version
+		}
+		profile.Mods[modReference] = mod
+	}
+}
+
+func (f *ficsitCLI) GetModOverrides(path string) map[string]settings.ModOverride {
+	overrides := settings.GetInstallationSettings(path).ModOverrides
+	if overrides == nil {
+		return make(map[string]settings.ModOverride)
+	}
+	return overrides
+}
+
+// SetModOverride changes how the mod of the installation's profile applies only to that installation, then updates the installed mods.
+// An empty override removes it.
+func (f *ficsitCLI) SetModOverride(path string, mod string, override settings.ModOverride) error {
+	installation := f.GetInstallation(path)
+	if installation == nil || !f.isValidInstall(path) {
+		return fmt.Errorf("invalid installation: %s", path)
+	}
+	if override.Version != "" && !utils.SemVerRegex.MatchString(override.Version) {
+		return fmt.Errorf("invalid version: %s", override.Version)
+	}
+
+	return f.action(installation, ActionModOverride, newItem(mod, override.Version), func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
+		err := settings.UpdateInstallationSettings(path, func(installationSettings *settings.InstallationSettings) {
+			if override.IsEmpty() {
+				delete(installationSettings.ModOverrides, mod)
+				return
+			}
+			if installationSettings.ModOverrides == nil {
+				installationSettings.ModOverrides = make(map[string]settings.ModOverride)
+			}
+			installationSettings.ModOverrides[mod] = override
+		})
+		if err != nil {
+			l.Error("failed to save mod override", slog.Any("error", err))
+			return fmt.Errorf("failed to save mod override: %w", err)
+		}
+
+		installErr := f.validateInstall(installation, taskUpdates)
+
+		if installErr != nil {
+			l.Error("failed to validate installation", slog.Any("error", installErr))
+			return installErr
+		}
+
+		return nil
+	})
+}
+
+func (f *ficsitCLI) RemoveModOverride(path string, mod string) error {
+	return f.SetModOverride(path, mod, settings.ModOverride{})
+}
@@ -13,35 +13,38 @@ import (
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 
 	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/utils"
 )
 
 func (f *ficsitCLI) SetProfile(profile string) error {
-	return f.action(ActionSelectProfile, newSimpleItem(profile), func(l *slog.Logger, taskChannel chan<- taskUpdate) error {
-		selectedInstallation := f.GetSelectedInstall()
+	selectedInstallation := f.GetSelectedInstall()
 
-		if selectedInstallation == nil {
-			l.Error("no installation selected")
-			return fmt.Errorf("no installation selected")
-		}
-		if selectedInstallation.Profile == profile {
-			return nil
-		}
+	if selectedInstallation == nil {
+		slog.Error("no installation selected")
+		return fmt.Errorf("no installation selected")
+	}
 
-		err := selectedInstallation.SetProfile(f.ficsitCli, profile)
-		if err != nil {
-			l.Error("failed to set profile", slog.Any("error", err))
-			return fmt.Errorf("failed to set profile: %w", err)
-		}
+	return f.setProfile(selectedInstallation, profile)
+}
 
-		err = f.ficsitCli.Installations.Save()
-		if err != nil {
-			l.Error("failed to save installations", slog.Any("error", err))
-		}
+func (f *ficsitCLI) setProfile(installation *cli.Installation, profile string) error {
+	return f.action(installation, ActionSelectProfile, newSimpleItem(profile), func(l *slog.Logger, taskChannel chan<- taskUpdate) error {
+		// The mods are installed even if the profile is already selected, so the installation matches it when this returns
+		if f.getInstallationProfile(installation) != profile {
+			var err error
+			f.updateInstallations(l, func() {
+				err = installation.SetProfile(f.ficsitCli, profile)
+			})
+			if err != nil {
+				l.Error("failed to set profile", slog.Any("error", err))
+				return fmt.Errorf("failed to set profile: %w", err)
+			}
 
-		f.EmitGlobals()
+			f.EmitGlobals()
+		}
 
-		installErr := f.validateInstall(selectedInstallation, taskChannel)
+		installErr := f.validateInstall(installation, taskChannel)
 
 		if installErr != nil {
 			l.Error("failed to validate installation", slog.Any("error", installErr))
@@ -57,10 +60,13 @@ func (f *ficsitCLI) GetSelectedProfile() *string {
 	if selectedInstallation == nil {
 		return nil
 	}
-	return &selectedInstallation.Profile
+	profile := f.getInstallationProfile(selectedInstallation)
+	return &profile
 }
 
 func (f *ficsitCLI) GetProfiles() []string {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
 	profileNames := make([]string, 0, len(f.ficsitCli.Profiles.Profiles))
 	for k := range f.ficsitCli.Profiles.Profiles {
 		profileNames = append(profileNames, k)
@@ -70,10 +76,24 @@ func (f *ficsitCLI) GetProfiles() []string {
 }
 
 func (f *ficsitCLI) GetProfile(profile string) *cli.Profile {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	return f.getProfile(profile)
+}
+
+// getProfile is GetProfile for callers that already hold profilesLock
+func (f *ficsitCLI) getProfile(profile string) *cli.Profile {
 	return f.ficsitCli.Profiles.GetProfile(profile)
 }
 
 func (f *ficsitCLI) GetFallbackProfile() string {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	return f.getFallbackProfile()
+}
+
+// getFallbackProfile is GetFallbackProfile for callers that already hold profilesLock
+func (f *ficsitCLI) getFallbackProfile() string {
 	fallbackProfile := cli.DefaultProfileName
 	if f.ficsitCli.Profiles.GetProfile(fallbackProfile) == nil {
 		// Pick first profile found
@@ -86,6 +106,13 @@ func (f *ficsitCLI) GetFallbackProfile() string {
 }
 
 func (f *ficsitCLI) GetFallbackProfileExcept(profile string) string {
+	f.profilesLock.RLock()
+	defer f.profilesLock.RUnlock()
+	return f.getFallbackProfileExcept(profile)
+}
+
+// getFallbackProfileExcept is GetFallbackProfileExcept for callers that already hold profilesLock
+func (f *ficsitCLI) getFallbackProfileExcept(profile string) string {
 	fallbackProfile := cli.DefaultProfileName
 	if f.ficsitCli.Profiles.GetProfile(fallbackProfile) == nil {
 		// Pick first profile found, that is not excluded
@@ -103,31 +130,56 @@ func (f *ficsitCLI) GetFallbackProfileExcept(profile string) string {
 func (f *ficsitCLI) AddProfile(name string) error {
 	l := slog.With(slog.String("task", "addProfile"), slog.String("profile", name))
 
-	_, err := f.ficsitCli.Profiles.AddProfile(name)
+	err := f.addProfile(l, name)
 	if err != nil {
 		l.Error("failed to add profile", slog.Any("error", err))
 		return fmt.Errorf("failed to add profile: %s: %w", name, err)
 	}
 
+	f.EmitGlobals()
+
+	return nil
+}
+
+func (f *ficsitCLI) addProfile(l *slog.Logger, name string) error {
+	f.profilesLock.Lock()
+	defer f.profilesLock.Unlock()
+
+	_, err := f.ficsitCli.Profiles.AddProfile(name)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	err = f.ficsitCli.Profiles.Save()
 	if err != nil {
 		l.Error("failed to save profile", slog.Any("error", err))
 	}
-
-	f.EmitGlobals()
-
 	return nil
 }
 
 func (f *ficsitCLI) RenameProfile(oldName string, newName string) error {
 	l := slog.With(slog.String("task", "renameProfile"), slog.String("oldName", oldName), slog.String("newName", newName))
 
-	err := f.ficsitCli.Profiles.RenameProfile(f.ficsitCli, oldName, newName)
+	err := f.renameProfile(l, oldName, newName)
 	if err != nil {
 		l.Error("failed to rename profile", slog.Any("error", err))
 		return fmt.Errorf("failed to rename profile: %s -> %s: %w", oldName, newName, err)
 	}
 
+	f.EmitGlobals()
+
+	return nil
+}
+
+func (f *ficsitCLI) renameProfile(l *slog.Logger, oldName string, newName string) error {
+	f.profilesLock.Lock()
+	defer f.profilesLock.Unlock()
+
+	err := f.ficsitCli.Profiles.RenameProfile(f.ficsitCli, oldName, newName)
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+
 	err = f.ficsitCli.Profiles.Save()
 	if err != nil {
 		l.Error("failed to save profile", slog.Any("error", err))
@@ -138,17 +190,29 @@ func (f *ficsitCLI) RenameProfile(oldName string, newName string) error {
 	if err != nil {
 		l.Error("failed to save installations", slog.Any("error", err))
 	}
+	return nil
+}
+
+func (f *ficsitCLI) DeleteProfile(name string) error {
+	l := slog.With(slog.String("task", "deleteProfile"), slog.String("profile", name))
+
+	err := f.deleteProfile(l, name)
+	if err != nil {
+		l.Error("failed to delete profile", slog.Any("error", err))
+		return fmt.Errorf("failed to delete profile: %s: %w", name, err)
+	}
 
 	f.EmitGlobals()
 
 	return nil
 }
 
-func (f *ficsitCLI) DeleteProfile(name string) error {
-	l := slog.With(slog.String("task", "deleteProfile"), slog.String("profile", name))
+func (f *ficsitCLI) deleteProfile(l *slog.Logger, name string) error {
+	f.profilesLock.Lock()
+	defer f.profilesLock.Unlock()
 
 	// ficsit-cli always sets installs that use the deleted profile to Default, which might not exist
-	fallbackProfile := f.GetFallbackProfileExcept(name)
+	fallbackProfile := f.getFallbackProfileExcept(name)
 	for _, installation := range f.ficsitCli.Installations.Installations {
 		if installation.Profile == name {
 			_ = installation.SetProfile(f.ficsitCli, fallbackProfile)
@@ -157,8 +221,7 @@ func (f *ficsitCLI) DeleteProfile(name string) error {
 
 	err := f.ficsitCli.Profiles.DeleteProfile(name)
 	if err != nil {
-		l.Error("failed to delete profile", slog.Any("error", err))
-		return fmt.Errorf("failed to delete profile: %s: %w", name, err)
+		return err //nolint:wrapcheck
 	}
 
 	err = f.ficsitCli.Profiles.Save()
@@ -171,9 +234,6 @@ func (f *ficsitCLI) DeleteProfile(name string) error {
 	if err != nil {
 		l.Error("failed to save installations", slog.Any("error", err))
 	}
-
-	f.EmitGlobals()
-
 	return nil
 }
 
@@ -185,6 +245,8 @@ type ExportedProfile struct {
 
 type ExportedProfileMetadata struct {
 	GameVersion int `json:"gameVersion"`
+	// GameVersionInfo is the full version of the game the profile was exported from, missing in older exports
+	GameVersionInfo *common.GameVersion `json:"gameVersionInfo,omitempty"`
 }
 
 func (f *ficsitCLI) MakeCurrentExportedProfile() (*ExportedProfile, error) {
@@ -203,24 +265,23 @@ func (f *ficsitCLI) MakeCurrentExportedProfile() (*ExportedProfile, error) {
 		return nil, fmt.Errorf("no profile selected")
 	}
 
-	profile := f.GetProfile(*profileName)
+	ctx := f.contextSnapshot()
+	profile := ctx.Profiles.GetProfile(*profileName)
 	if profile == nil {
 		l.Error("profile not found", slog.String("profile", *profileName))
 		return nil, fmt.Errorf("profile not found")
 	}
-	lockfile, err := selectedInstallation.LockFile(f.ficsitCli)
+	lockfile, err := selectedInstallation.LockFile(ctx)
 	if err != nil {
 		l.Error("failed to get lockfile", slog.Any("error", err))
 		return nil, fmt.Errorf("failed to get lockfile: %w", err)
 	}
 
 	installMetadata, ok := f.installationMetadata.Load(selectedInstallation.Path)
-	var gameVersion int
+	metadata := &ExportedProfileMetadata{}
 	if ok && installMetadata.Info != nil {
-		gameVersion = installMetadata.Info.Version
-	}
-	metadata := &ExportedProfileMetadata{
-		GameVersion: gameVersion,
+		metadata.GameVersion = installMetadata.Info.Version
+		metadata.GameVersionInfo = installMetadata.Info.GameVersion
 	}
 
 	if lockfile == nil {
@@ -296,15 +357,15 @@ func (f *ficsitCLI) ReadExportedProfileMetadata(file string) (*ExportedProfileMe
 }
 
 func (f *ficsitCLI) ImportProfile(name string, file string) error {
-	return f.action(ActionImportProfile, newSimpleItem(name), func(l *slog.Logger, taskChannel chan<- taskUpdate) error {
-		l = l.With(slog.String("file", file))
+	selectedInstallation := f.GetSelectedInstall()
 
-		selectedInstallation := f.GetSelectedInstall()
+	if selectedInstallation == nil {
+		slog.Error("no installation selected")
+		return fmt.Errorf("no installation selected")
+	}
 
-		if selectedInstallation == nil {
-			l.Error("no installation selected")
-			return fmt.Errorf("no installation selected")
-		}
+	return f.action(selectedInstallation, ActionImportProfile, newSimpleItem(name), func(l *slog.Logger, taskChannel chan<- taskUpdate) error {
+		l = l.With(slog.String("file", file))
 
 		profileData, err := os.ReadFile(file)
 		if err != nil {
@@ -319,22 +380,26 @@ func (f *ficsitCLI) ImportProfile(name string, file string) error {
 			return fmt.Errorf("failed to read profile file: %w", err)
 		}
 
+		currentProfile := f.getInstallationProfile(selectedInstallation)
+
+		f.profilesLock.Lock()
 		profile, err := f.ficsitCli.Profiles.AddProfile(name)
+		if err == nil {
+			profile.Mods = exportedProfile.Profile.Mods
+			_ = selectedInstallation.SetProfile(f.ficsitCli, name)
+		}
+		f.profilesLock.Unlock()
 		if err != nil {
 			l.Error("failed to add profile", slog.Any("error", err))
 			return fmt.Errorf("failed to add imported profile: %w", err)
 		}
 
-		profile.Mods = exportedProfile.Profile.Mods
-
-		currentProfile := selectedInstallation.Profile
-
-		_ = selectedInstallation.SetProfile(f.ficsitCli, name)
-
-		err = selectedInstallation.WriteLockFile(f.ficsitCli, &exportedProfile.LockFile)
+		err = selectedInstallation.WriteLockFile(f.contextSnapshot(), &exportedProfile.LockFile)
 		if err != nil {
+			f.profilesLock.Lock()
 			_ = selectedInstallation.SetProfile(f.ficsitCli, currentProfile)
 			_ = f.ficsitCli.Profiles.DeleteProfile(name)
+			f.profilesLock.Unlock()
 			l.Error("failed to write lockfile", slog.Any("error", err))
 			return fmt.Errorf("failed to write profile: %w", err)
 		}
@@ -344,12 +409,16 @@ func (f *ficsitCLI) ImportProfile(name string, file string) error {
 		installErr := f.validateInstall(selectedInstallation, taskChannel)
 
 		if installErr != nil {
+			f.profilesLock.Lock()
 			_ = f.ficsitCli.Profiles.DeleteProfile(name)
+			f.profilesLock.Unlock()
 			l.Error("failed to validate installation", slog.Any("error", installErr))
 			return installErr
 		}
 
+		f.profilesLock.Lock()
 		err = f.ficsitCli.Profiles.Save()
+		f.profilesLock.Unlock()
 		if err != nil {
 			l.Error("failed to save profile", slog.Any("error", err))
 		}
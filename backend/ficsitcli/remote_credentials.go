@@ -0,0 +1,360 @@
+package ficsitcli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"sync"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/remote"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
+)
+
+// splitRemotePassword removes the password from a remote path.
+// It returns the path that can be stored, the ID of the credential it references, and the removed password, if any.
+func splitRemotePassword(path string) (string, string, string, bool, error) {
+	parsed, err := url.Parse(path)
+	if err != nil {
+		return "", "", "", false, fmt.Errorf("failed to parse path: %w", err)
+	}
+	credentialID := remoteCredentialID(parsed)
+	if parsed.User == nil {
+		return path, credentialID, "", false, nil
+	}
+	password, hasPassword := parsed.User.Password()
+	if !hasPassword {
+		return path, credentialID, "", false, nil
+	}
+	parsed.User = url.User(parsed.User.Username())
+	return parsed.String(), credentialID, password, true, nil
+}
+
+func remoteCredentialID(parsed *url.URL) string {
+	return credentials.ID(parsed.Scheme, parsed.User.Username(), parsed.Host)
+}
+
+// prepareRemoteCredential removes the password from a remote path, and picks the credential to connect with.
+// That is the given credential if set, otherwise the password in the path, otherwise the stored credential.
+// It returns the path that can be stored, the ID of the credential it references, and the credential.
+func prepareRemoteCredential(path string, credential credentials.Credential) (string, string, credentials.Credential, error) {
+	storedPath, credentialID, password, hasPassword, err := splitRemotePassword(path)
+	if err != nil {
+		return "", "", credentials.Credential{}, err
+	}
+	if credential.IsEmpty() && !hasPassword {
+		credential, _ = credentials.Get(credentialID)
+	} else if credential.Password == "" && hasPassword {
+		credential.Password = password
+	}
+	return storedPath, credentialID, credential, nil
+}
+
+// openRemoteDisk connects to the server at path, which must be a remote path, using the credential
+func openRemoteDisk(path string, credential credentials.Credential) (disk.Disk, error) {
+	parsed, err := url.Parse(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse path: %w", err)
+	}
+
+	if parsed.Scheme == "sftp" {
+		d, err := remote.NewSFTP(parsed, credential, remote.HostKeys.Callback)
+		if err != nil {
+			var unknownHostKeyErr *remote.UnknownHostKeyError
+			if errors.As(err, &unknownHostKeyErr) && appCommon.AppContext != nil {
+				wailsRuntime.EventsEmit(appCommon.AppContext, "unknownHostKey", unknownHostKeyErr)
+			}
+			return nil, err
+		}
+		return d, nil
+	}
+
+	if credential.Password != "" {
+		parsed.User = url.UserPassword(parsed.User.Username(), credential.Password)
+	}
+	if parsed.Scheme == "ftp" {
+		return remote.NewFTP(parsed) //nolint:wrapcheck
+	}
+	d, err := disk.FromPath(parsed.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	return d, nil
+}
+
+func closeDisk(d disk.Disk) {
+	if closer, ok := d.(io.Closer); ok {
+		err := closer.Close()
+		if err != nil {
+			slog.Warn("failed to close disk", slog.Any("error", err))
+		}
+	}
+}
+
+// TrustRemoteHostKey pins the SFTP host key the user confirmed after an unknownHostKey event
+func (f *ficsitCLI) TrustRemoteHostKey(host string, fingerprint string) error {
+	err := remote.HostKeys.Trust(host, fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to trust host key: %w", err)
+	}
+	return nil
+}
+
+// ForgetRemoteHostKey removes the SFTP host key pinned for host, for example after the server was reinstalled
+func (f *ficsitCLI) ForgetRemoteHostKey(host string) error {
+	err := remote.HostKeys.Forget(host)
+	if err != nil {
+		return fmt.Errorf("failed to forget host key: %w", err)
+	}
+	return nil
+}
+
+// initRemoteCredentials moves any passwords still stored in installations.json to the credential store,
+// and makes remote installations connect using the stored credentials
+func (f *ficsitCLI) initRemoteCredentials() error {
+	migrated := false
+	sftpHosts := make([]string, 0)
+	for _, installation := range f.ficsitCli.Installations.Installations {
+		local, err := isLocal(installation.Path)
+		if err != nil || local {
+			continue
+		}
+
+		if parsed, err := url.Parse(installation.Path); err == nil && parsed.Scheme == "sftp" {
+			sftpHosts = append(sftpHosts, parsed.Host)
+		}
+
+		storedPath, credentialID, password, hasPassword, err := splitRemotePassword(installation.Path)
+		if err != nil {
+			slog.Warn("failed to parse remote installation path", slog.Any("error", err), slog.String("path", installation.Path))
+			continue
+		}
+
+		if hasPassword {
+			err := credentials.Set(credentialID, credentials.Credential{Password: password})
+			if err != nil {
+				return fmt.Errorf("failed to store credentials: %w", err)
+			}
+			if f.ficsitCli.Installations.SelectedInstallation == installation.Path {
+				f.ficsitCli.Installations.SelectedInstallation = storedPath
+			}
+			installation.Path = storedPath
+			migrated = true
+		}
+
+		installation.DiskInstance = newRemoteDisk(installation.Path)
+	}
+
+	if migrated {
+		err := f.ficsitCli.Installations.Save()
+		if err != nil {
+			return fmt.Errorf("failed to save installations: %w", err)
+		}
+	}
+
+	// SFTP servers added before host keys were checked would otherwise all fail to connect until their key is trusted
+	err := remote.HostKeys.TrustOnFirstUse(sftpHosts)
+	if err != nil {
+		slog.Error("failed to trust host keys of existing sftp servers", slog.Any("error", err))
+	}
+	return nil
+}
+
+func (f *ficsitCLI) isCredentialUsed(credentialID string) bool {
+	for _, installation := range f.getInstallationsSnapshot() {
+		parsed, err := url.Parse(installation.Path)
+		if err != nil {
+			continue
+		}
+		if _, ok := remoteSchemes[parsed.Scheme]; !ok {
+			continue
+		}
+		if remoteCredentialID(parsed) == credentialID {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateRemoteServerCredentials changes the username and credential used to connect to a remote server,
+// without having to remove and re-add it. Changing the username changes the path of the installation.
+func (f *ficsitCLI) UpdateRemoteServerCredentials(path string, username string, credential credentials.Credential) error {
+	l := slog.With(slog.String("task", "updateRemoteServerCredentials"), slog.String("path", path))
+
+	installation := f.GetInstallation(path)
+	if installation == nil {
+		return fmt.Errorf("installation not found")
+	}
+	if metadata, ok := f.installationMetadata.Load(path); ok && metadata.State == InstallStateLoading {
+		return fmt.Errorf("installation is still loading")
+	}
+
+	parsed, err := url.Parse(path)
+	if err != nil {
+		return fmt.Errorf("failed to parse path: %w", err)
+	}
+	if _, ok := remoteSchemes[parsed.Scheme]; !ok {
+		return fmt.Errorf("installation is not remote")
+	}
+
+	lock := f.installLock(path)
+	if !lock.TryLock() {
+		return fmt.Errorf("another operation in progress")
+	}
+	defer lock.Unlock()
+
+	oldCredentialID := remoteCredentialID(parsed)
+	if username == "" {
+		parsed.User = nil
+	} else {
+		parsed.User = url.User(username)
+	}
+	newPath := parsed.String()
+	newCredentialID := remoteCredentialID(parsed)
+
+	if newPath != path && f.GetInstallation(newPath) != nil {
+		return fmt.Errorf("installation already exists")
+	}
+
+	err = credentials.Set(newCredentialID, credential)
+	if err != nil {
+		return fmt.Errorf("failed to store credentials: %w", err)
+	}
+
+	if newPath != path {
+		f.updateInstallations(l, func() {
+			installation.Path = newPath
+			if f.ficsitCli.Installations.SelectedInstallation == path {
+				f.ficsitCli.Installations.SelectedInstallation = newPath
+			}
+		})
+		f.installationMetadata.Delete(path)
+		f.installLocks.Delete(path)
+		err = settings.MoveInstallationSettings(path, newPath)
+		if err != nil {
+			l.Error("failed to move installation settings", slog.Any("error", err))
+		}
+
+		if !f.isCredentialUsed(oldCredentialID) {
+			err = credentials.Delete(oldCredentialID)
+			if err != nil {
+				l.Error("failed to delete old credentials", slog.Any("error", err))
+			}
+		}
+	}
+
+	if installation.DiskInstance != nil {
+		closeDisk(installation.DiskInstance)
+	}
+	installation.DiskInstance = newRemoteDisk(newPath)
+
+	f.installationMetadata.Store(newPath, installationMetadata{
+		State: InstallStateLoading,
+	})
+	f.EmitGlobals()
+	f.fetchRemoteInstallationMetadata(installation)
+	return nil
+}
+
+// remoteDisk connects to the remote server on first use, with the credentials from the credential store
+type remoteDisk struct {
+	path string
+	lock sync.Mutex
+	d    disk.Disk
+}
+
+var _ disk.Disk = (*remoteDisk)(nil)
+
+func newRemoteDisk(path string) *remoteDisk {
+	return &remoteDisk{path: path}
+}
+
+func (r *remoteDisk) get() (disk.Disk, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.d != nil {
+		return r.d, nil
+	}
+	_, _, credential, err := prepareRemoteCredential(r.path, credentials.Credential{})
+	if err != nil {
+		return nil, err
+	}
+	d, err := openRemoteDisk(r.path, credential)
+	if err != nil {
+		return nil, err
+	}
+	r.d = d
+	return d, nil
+}
+
+func (r *remoteDisk) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.d != nil {
+		closeDisk(r.d)
+		r.d = nil
+	}
+	return nil
+}
+
+func (r *remoteDisk) Exists(path string) (bool, error) {
+	d, err := r.get()
+	if err != nil {
+		return false, err
+	}
+	return d.Exists(path) //nolint:wrapcheck
+}
+
+func (r *remoteDisk) Read(path string) ([]byte, error) {
+	d, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return d.Read(path) //nolint:wrapcheck
+}
+
+func (r *remoteDisk) Write(path string, data []byte) error {
+	d, err := r.get()
+	if err != nil {
+		return err
+	}
+	return d.Write(path, data) //nolint:wrapcheck
+}
+
+func (r *remoteDisk) Remove(path string) error {
+	d, err := r.get()
+	if err != nil {
+		return err
+	}
+	return d.Remove(path) //nolint:wrapcheck
+}
+
+func (r *remoteDisk) MkDir(path string) error {
+	d, err := r.get()
+	if err != nil {
+		return err
+	}
+	return d.MkDir(path) //nolint:wrapcheck
+}
+
+func (r *remoteDisk) ReadDir(path string) ([]disk.Entry, error) {
+	d, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return d.ReadDir(path) //nolint:wrapcheck
+}
+
+func (r *remoteDisk) Open(path string, flag int) (io.WriteCloser, error) {
+	d, err := r.get()
+	if err != nil {
+		return nil, err
+	}
+	return d.Open(path, flag) //nolint:wrapcheck
+}
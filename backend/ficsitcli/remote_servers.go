@@ -3,8 +3,17 @@ package ficsitcli
 import (
 	"fmt"
 	"log/slog"
+	"net/url"
+	"path/filepath"
 
+	"github.com/satisfactorymodding/ficsit-cli/cli"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/remote"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
 )
 
 func (f *ficsitCLI) GetRemoteInstallations() []string {
@@ -23,19 +32,60 @@ func (f *ficsitCLI) GetRemoteInstallations() []string {
 }
 
 func (f *ficsitCLI) AddRemoteServer(path string) error {
-	if f.ficsitCli.Installations.GetInstallation(path) != nil {
+	return f.AddRemoteServerWithCredential(path, credentials.Credential{})
+}
+
+// AddRemoteServerWithCredential adds a remote server, connecting with the given credential instead of the password in the path.
+// This allows SFTP servers using key or agent authentication.
+func (f *ficsitCLI) AddRemoteServerWithCredential(path string, credential credentials.Credential) error {
+	storedPath, credentialID, credential, err := prepareRemoteCredential(path, credential)
+	if err != nil {
+		return err
+	}
+	if f.GetInstallation(storedPath) != nil {
 		return fmt.Errorf("installation already exists")
 	}
-	l := slog.With(slog.String("task", "addRemoteServer"), slog.String("path", path))
+	l := slog.With(slog.String("task", "addRemoteServer"), slog.String("path", storedPath))
 
-	installation, err := f.ficsitCli.Installations.AddInstallation(f.ficsitCli, path, f.GetFallbackProfile())
+	d, err := openRemoteDisk(storedPath, credential)
 	if err != nil {
-		return fmt.Errorf("failed to add installation: %w", err)
+		return fmt.Errorf("failed to connect: %w", err)
 	}
 
-	err = f.ficsitCli.Installations.Save()
+	installation := &cli.Installation{
+		Path:         storedPath,
+		Profile:      f.GetFallbackProfile(),
+		DiskInstance: d,
+	}
+	err = installation.Validate(f.ficsitCli)
 	if err != nil {
-		l.Error("failed to save installations", slog.Any("error", err))
+		closeDisk(d)
+		return fmt.Errorf("failed to add installation: %w", err)
+	}
+
+	if !credential.IsEmpty() {
+		err = credentials.Set(credentialID, credential)
+		if err != nil {
+			closeDisk(d)
+			return fmt.Errorf("failed to store credentials: %w", err)
+		}
+	}
+
+	var alreadyExists bool
+	f.updateInstallations(l, func() {
+		// The installation might have been added while connecting, so check again while holding the lock,
+		// the same way ficsit-cli's AddInstallation does
+		for _, existing := range f.ficsitCli.Installations.Installations {
+			if filepath.Clean(existing.Path) == filepath.Clean(installation.Path) {
+				alreadyExists = true
+				return
+			}
+		}
+		f.ficsitCli.Installations.Installations = append(f.ficsitCli.Installations.Installations, installation)
+	})
+	if alreadyExists {
+		closeDisk(d)
+		return fmt.Errorf("installation already exists")
 	}
 
 	meta, err := f.getRemoteServerMetadata(installation)
@@ -43,7 +93,7 @@ func (f *ficsitCLI) AddRemoteServer(path string) error {
 		return fmt.Errorf("failed to get remote server metadata: %w", err)
 	}
 
-	f.installationMetadata.Store(path, installationMetadata{
+	f.installationMetadata.Store(storedPath, installationMetadata{
 		State: InstallStateValid,
 		Info:  meta,
 	})
@@ -53,6 +103,26 @@ func (f *ficsitCLI) AddRemoteServer(path string) error {
 	return nil
 }
 
+// DiagnoseRemote checks each step of connecting to a remote server, to find which one fails for a path that can't be added
+func (f *ficsitCLI) DiagnoseRemote(path string) remote.Diagnosis {
+	return f.DiagnoseRemoteWithCredential(path, credentials.Credential{})
+}
+
+func (f *ficsitCLI) DiagnoseRemoteWithCredential(path string, credential credentials.Credential) remote.Diagnosis {
+	_, _, credential, err := prepareRemoteCredential(path, credential)
+	if err != nil {
+		// The diagnosis reports the path being invalid
+		credential = credentials.Credential{}
+	}
+	diagnosis := remote.Diagnose(path, credential, remote.HostKeys.Callback)
+	for _, stage := range diagnosis.Stages {
+		if stage.UnknownHostKey != nil && appCommon.AppContext != nil {
+			wailsRuntime.EventsEmit(appCommon.AppContext, "unknownHostKey", stage.UnknownHostKey)
+		}
+	}
+	return diagnosis
+}
+
 func (f *ficsitCLI) RemoveRemoteServer(path string) error {
 	metadata, ok := f.installationMetadata.Load(path)
 	if !ok {
@@ -64,15 +134,40 @@ func (f *ficsitCLI) RemoveRemoteServer(path string) error {
 	if metadata.Info != nil && metadata.Info.Location != common.LocationTypeRemote {
 		return fmt.Errorf("installation is not remote")
 	}
-	err := f.ficsitCli.Installations.DeleteInstallation(path)
+
+	lock := f.installLock(path)
+	if !lock.TryLock() {
+		return fmt.Errorf("another operation in progress")
+	}
+	defer lock.Unlock()
+
+	if installation := f.GetInstallation(path); installation != nil && installation.DiskInstance != nil {
+		closeDisk(installation.DiskInstance)
+	}
+	var err error
+	f.updateInstallations(slog.Default(), func() {
+		err = f.ficsitCli.Installations.DeleteInstallation(path)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to delete installation: %w", err)
 	}
-	err = f.ficsitCli.Installations.Save()
+	f.installationMetadata.Delete(path)
+	f.installLocks.Delete(path)
+	err = settings.DeleteInstallationSettings(path)
 	if err != nil {
-		slog.Error("failed to save installations", slog.Any("error", err))
+		slog.Error("failed to delete installation settings", slog.Any("error", err))
 	}
-	f.installationMetadata.Delete(path)
+
+	if parsed, err := url.Parse(path); err == nil {
+		credentialID := remoteCredentialID(parsed)
+		if !f.isCredentialUsed(credentialID) {
+			err = credentials.Delete(credentialID)
+			if err != nil {
+				slog.Error("failed to delete credentials", slog.Any("error", err))
+			}
+		}
+	}
+
 	f.EmitGlobals()
 	return nil
 }
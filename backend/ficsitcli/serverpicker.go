@@ -1,9 +1,11 @@
 package ficsitcli
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"log/slog"
 	"net/url"
 	"path"
 	"path/filepath"
@@ -11,24 +13,49 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
 	psUtilDisk "github.com/shirou/gopsutil/v3/disk"
 	"golang.org/x/sync/errgroup"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
 )
 
 type serverPicker struct {
-	disks              map[string]diskData
-	nextServerPickerID int
+	lock               sync.Mutex
+	sessions           map[string]*pickerSession
+	nextServerPickerID atomic.Int64
+	expiryOnce         sync.Once
 }
 
-type diskData struct {
+// pickerSession is a disk opened by StartPicker, kept until StopPicker is called, or it is left idle for too long
+type pickerSession struct {
 	disk    disk.Disk
 	isLocal bool
+	path    string
+
+	startedAt time.Time
+	lastUsed  time.Time
+	// Number of calls currently using the session, which prevents it from expiring
+	inUse int
+	// Set once the session is stopped. Its disk is closed when the last call using it is done.
+	stopping bool
+
+	// Cancelled when the session is stopped, to end any search in progress
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
+const (
+	pickerIdleTimeout    = 10 * time.Minute
+	pickerExpiryInterval = time.Minute
+)
+
 var ServerPicker = &serverPicker{
-	disks: make(map[string]diskData),
+	sessions: make(map[string]*pickerSession),
 }
 
 type PickerDirectory struct {
@@ -42,10 +69,81 @@ type PickerResult struct {
 	Items          []PickerDirectory `json:"items"`
 }
 
+type PickerSession struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`
+	IsRemote  bool      `json:"isRemote"`
+	StartedAt time.Time `json:"startedAt"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
 func (s *serverPicker) getID() string {
-	id := s.nextServerPickerID
-	s.nextServerPickerID++
-	return strconv.Itoa(id)
+	return strconv.FormatInt(s.nextServerPickerID.Add(1)-1, 10)
+}
+
+// acquire returns the session with the given id, marking it as in use until release is called
+func (s *serverPicker) acquire(id string) (*pickerSession, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("no such disk: %s", id)
+	}
+	session.inUse++
+	session.lastUsed = time.Now()
+	return session, nil
+}
+
+func (s *serverPicker) release(session *pickerSession) {
+	s.lock.Lock()
+	session.inUse--
+	session.lastUsed = time.Now()
+	closeNow := session.stopping && session.inUse == 0
+	s.lock.Unlock()
+
+	if closeNow {
+		closeDisk(session.disk)
+	}
+}
+
+// stopLocked cancels the session, returning whether its disk can be closed now, or is left for release to close
+// once the calls using it are done. s.lock must be held.
+func (p *pickerSession) stopLocked() bool {
+	p.stopping = true
+	p.cancel()
+	return p.inUse == 0
+}
+
+func (s *serverPicker) startExpiry() {
+	s.expiryOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(pickerExpiryInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				s.expireIdle(time.Now().Add(-pickerIdleTimeout))
+			}
+		}()
+	})
+}
+
+// expireIdle stops the sessions that are not in use and were last used before the deadline
+func (s *serverPicker) expireIdle(deadline time.Time) {
+	s.lock.Lock()
+	expired := make([]*pickerSession, 0)
+	for id, session := range s.sessions {
+		if session.inUse == 0 && session.lastUsed.Before(deadline) {
+			delete(s.sessions, id)
+			if session.stopLocked() {
+				expired = append(expired, session)
+			}
+			slog.Info("server picker session expired", slog.String("id", id))
+		}
+	}
+	s.lock.Unlock()
+
+	for _, session := range expired {
+		closeDisk(session.disk)
+	}
 }
 
 func (*serverPicker) GetPathSeparator() string {
@@ -68,39 +166,115 @@ func isLocal(path string) (bool, error) {
 }
 
 func (s *serverPicker) StartPicker(path string) (string, error) {
-	id := s.getID()
+	return s.StartPickerWithCredential(path, credentials.Credential{})
+}
 
-	d, err := disk.FromPath(path)
+// StartPickerWithCredential starts a picker on a remote server, connecting with the given credential
+// instead of the password in the path. This allows SFTP servers using key or agent authentication.
+func (s *serverPicker) StartPickerWithCredential(path string, credential credentials.Credential) (string, error) {
+	local, err := isLocal(path)
 	if err != nil {
-		return "", fmt.Errorf("failed to create: %w", err)
+		return "", fmt.Errorf("failed to check if local: %w", err)
 	}
 
-	local, err := isLocal(path)
+	var d disk.Disk
+	sessionPath := path
+	if local {
+		d, err = disk.FromPath(path)
+	} else {
+		var connectCredential credentials.Credential
+		sessionPath, _, connectCredential, err = prepareRemoteCredential(path, credential)
+		if err == nil {
+			d, err = openRemoteDisk(path, connectCredential)
+		}
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to check if local: %w", err)
+		return "", fmt.Errorf("failed to create: %w", err)
 	}
 
-	s.disks[id] = diskData{
-		disk:    d,
-		isLocal: local,
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+
+	id := s.getID()
+
+	s.lock.Lock()
+	s.sessions[id] = &pickerSession{
+		disk:      d,
+		isLocal:   local,
+		path:      sessionPath,
+		startedAt: now,
+		lastUsed:  now,
+		ctx:       ctx,
+		cancel:    cancel,
 	}
+	s.lock.Unlock()
+
+	s.startExpiry()
 
 	return id, nil
 }
 
 func (s *serverPicker) StopPicker(id string) error {
-	if _, ok := s.disks[id]; !ok {
+	s.lock.Lock()
+	session, ok := s.sessions[id]
+	if !ok {
+		s.lock.Unlock()
 		return fmt.Errorf("no such disk: %s", id)
 	}
-	delete(s.disks, id)
+	delete(s.sessions, id)
+	closeNow := session.stopLocked()
+	s.lock.Unlock()
+
+	if closeNow {
+		closeDisk(session.disk)
+	}
 	return nil
 }
 
+// StopAllPickers stops every picker session, for example after the picker dialog was closed without stopping its own
+func (s *serverPicker) StopAllPickers() {
+	s.lock.Lock()
+	toClose := make([]*pickerSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		if session.stopLocked() {
+			toClose = append(toClose, session)
+		}
+	}
+	s.sessions = make(map[string]*pickerSession)
+	s.lock.Unlock()
+
+	for _, session := range toClose {
+		closeDisk(session.disk)
+	}
+}
+
+// GetPickers lists the active picker sessions
+func (s *serverPicker) GetPickers() []PickerSession {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make([]PickerSession, 0, len(s.sessions))
+	for id, session := range s.sessions {
+		result = append(result, PickerSession{
+			ID:        id,
+			Path:      session.path,
+			IsRemote:  !session.isLocal,
+			StartedAt: session.startedAt,
+			LastUsed:  session.lastUsed,
+		})
+	}
+	slices.SortFunc(result, func(a, b PickerSession) int {
+		return a.StartedAt.Compare(b.StartedAt)
+	})
+	return result
+}
+
 func (s *serverPicker) TryPick(id string, pickPath string) (PickerResult, error) {
-	d, ok := s.disks[id]
-	if !ok {
-		return PickerResult{}, fmt.Errorf("no such disk: %s", id)
+	d, err := s.acquire(id)
+	if err != nil {
+		return PickerResult{}, err
 	}
+	defer s.release(d)
 
 	result := PickerResult{
 		Items: make([]PickerDirectory, 0),
@@ -127,8 +301,6 @@ func (s *serverPicker) TryPick(id string, pickPath string) (PickerResult, error)
 		return result, nil
 	}
 
-	var err error
-
 	result.IsValidInstall, err = isValidInstall(d.disk, pickPath)
 	if err != nil {
 		return PickerResult{}, fmt.Errorf("failed to check if valid install: %w", err)
@@ -0,0 +1,204 @@
+package ficsitcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	psUtilDisk "github.com/shirou/gopsutil/v3/disk"
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+)
+
+const (
+	defaultSearchDepth = 8
+	maxSearchDepth     = 16
+	searchTimeout      = 2 * time.Minute
+	// Every listed directory counts as a request
+	maxSearchRequests = 5000
+	// FTP disks only keep a handful of connections, so more would just queue up
+	searchConcurrency = 5
+)
+
+var serverExecutables = []string{"FactoryServer.sh", "FactoryServer.exe"}
+
+// Directories at the root of a filesystem that can never contain a server, and are expensive or unsafe to walk.
+// Directories with the same name deeper in the tree, such as ~/dev, are searched.
+var searchSkippedDirectories = []string{"proc", "sys", "dev", "$Recycle.Bin", "System Volume Information"}
+
+type PickerSearchResult struct {
+	Installations       []PickerDirectory `json:"installations"`
+	Searched            int64             `json:"searched"`
+	TimedOut            bool              `json:"timedOut"`
+	RequestLimitReached bool              `json:"requestLimitReached"`
+}
+
+type pickerSearch struct {
+	ctx      context.Context
+	disk     disk.Disk
+	isLocal  bool
+	maxDepth int
+
+	wg                  sync.WaitGroup
+	semaphore           chan struct{}
+	requests            atomic.Int64
+	requestLimitReached atomic.Bool
+
+	foundLock sync.Mutex
+	found     []PickerDirectory
+	onFound   func(PickerDirectory)
+}
+
+// SearchPicker looks for server installations in root and its subdirectories, up to maxDepth levels deep.
+// Each installation is sent in a serverPickerSearchResult event as soon as it is found,
+// and all of them are returned once the search completes, hits its time or request limit, or the picker is stopped.
+func (s *serverPicker) SearchPicker(id string, root string, maxDepth int) (PickerSearchResult, error) {
+	d, err := s.acquire(id)
+	if err != nil {
+		return PickerSearchResult{}, err
+	}
+	defer s.release(d)
+
+	if maxDepth <= 0 {
+		maxDepth = defaultSearchDepth
+	}
+	maxDepth = min(maxDepth, maxSearchDepth)
+
+	l := slog.With(slog.String("task", "searchPicker"), slog.Int("maxDepth", maxDepth))
+
+	ctx, cancel := context.WithTimeout(d.ctx, searchTimeout)
+	defer cancel()
+
+	search := &pickerSearch{
+		ctx:       ctx,
+		disk:      d.disk,
+		isLocal:   d.isLocal,
+		maxDepth:  maxDepth,
+		semaphore: make(chan struct{}, searchConcurrency),
+		found:     make([]PickerDirectory, 0),
+		onFound: func(item PickerDirectory) {
+			if common.AppContext != nil {
+				wailsRuntime.EventsEmit(common.AppContext, "serverPickerSearchResult", id, item)
+			}
+		},
+	}
+
+	if d.isLocal && root == "\\" && runtime.GOOS == "windows" {
+		// On windows, the root does not exist, and instead we need to search each partition
+		partitions, err := psUtilDisk.Partitions(false)
+		if err != nil {
+			return PickerSearchResult{}, fmt.Errorf("failed to get partitions: %w", err)
+		}
+		for _, partition := range partitions {
+			search.wg.Add(1)
+			go search.walk(partition.Mountpoint+"\\", 1)
+		}
+	} else {
+		search.wg.Add(1)
+		go search.walk(root, 0)
+	}
+
+	search.wg.Wait()
+
+	slices.SortFunc(search.found, func(a, b PickerDirectory) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	result := PickerSearchResult{
+		Installations:       search.found,
+		Searched:            min(search.requests.Load(), maxSearchRequests),
+		TimedOut:            errors.Is(ctx.Err(), context.DeadlineExceeded),
+		RequestLimitReached: search.requestLimitReached.Load(),
+	}
+
+	l.Info("search complete", slog.Int("found", len(result.Installations)), slog.Int64("searched", result.Searched), slog.Bool("timedOut", result.TimedOut), slog.Bool("requestLimitReached", result.RequestLimitReached))
+
+	return result, nil
+}
+
+func (p *pickerSearch) join(dir string, name string) string {
+	if p.isLocal {
+		return filepath.Join(dir, name)
+	}
+	return path.Join(dir, name)
+}
+
+func (p *pickerSearch) walk(dir string, depth int) {
+	defer p.wg.Done()
+
+	if p.requests.Add(1) > maxSearchRequests {
+		p.requestLimitReached.Store(true)
+		return
+	}
+
+	select {
+	case p.semaphore <- struct{}{}:
+	case <-p.ctx.Done():
+		return
+	}
+	entries, err := p.disk.ReadDir(dir)
+	<-p.semaphore
+	if err != nil {
+		// Most likely a permission error, which should not stop the search
+		slog.Debug("failed to read directory during search", slog.Any("error", err))
+		return
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && slices.Contains(serverExecutables, entry.Name()) {
+			item := PickerDirectory{
+				Name:           p.baseName(dir),
+				Path:           dir,
+				IsValidInstall: true,
+			}
+			p.foundLock.Lock()
+			p.found = append(p.found, item)
+			p.foundLock.Unlock()
+			p.onFound(item)
+			// Servers are not nested in other servers
+			return
+		}
+	}
+
+	if depth >= p.maxDepth {
+		return
+	}
+
+	isRoot := p.isRoot(dir)
+	for _, entry := range entries {
+		if !entry.IsDir() || (isRoot && slices.Contains(searchSkippedDirectories, entry.Name())) {
+			continue
+		}
+		if p.ctx.Err() != nil {
+			return
+		}
+		p.wg.Add(1)
+		go p.walk(p.join(dir, entry.Name()), depth+1)
+	}
+}
+
+// isRoot returns whether dir is the root of a filesystem, or of a drive on Windows
+func (p *pickerSearch) isRoot(dir string) bool {
+	if p.isLocal {
+		return filepath.Dir(dir) == filepath.Clean(dir)
+	}
+	return path.Clean("/"+dir) == "/"
+}
+
+func (p *pickerSearch) baseName(dir string) string {
+	if p.isLocal {
+		return filepath.Base(dir)
+	}
+	return path.Base(dir)
+}
@@ -0,0 +1,73 @@
+package ficsitcli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+// closeCountingDisk is a disk.Disk that only records being closed
+type closeCountingDisk struct {
+	disk.Disk
+	closed int
+}
+
+func (d *closeCountingDisk) Close() error {
+	d.closed++
+	return nil
+}
+
+func TestStopPickerWaitsForUsers(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		stop func(s *serverPicker, id string)
+	}{
+		{
+			name: "StopPicker",
+			stop: func(s *serverPicker, id string) {
+				if err := s.StopPicker(id); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "StopAllPickers",
+			stop: func(s *serverPicker, _ string) {
+				s.StopAllPickers()
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			d := &closeCountingDisk{}
+			ctx, cancel := context.WithCancel(context.Background())
+			s := &serverPicker{
+				sessions: map[string]*pickerSession{
+					"0": {disk: d, lastUsed: time.Now(), ctx: ctx, cancel: cancel},
+				},
+			}
+
+			session, err := s.acquire("0")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			test.stop(s, "0")
+			if ctx.Err() == nil {
+				t.Errorf("expected the session to be cancelled when stopped")
+			}
+			if d.closed != 0 {
+				t.Errorf("expected the disk to stay open while in use, closed %d times", d.closed)
+			}
+			if _, err := s.acquire("0"); err == nil {
+				t.Errorf("expected the stopped session to be gone")
+			}
+
+			s.release(session)
+			if d.closed != 1 {
+				t.Errorf("expected the disk to be closed once the last user released it, closed %d times", d.closed)
+			}
+		})
+	}
+}
@@ -28,12 +28,16 @@ const (
 	ActionSelectProfile Action = "selectProfile"
 	ActionImportProfile Action = "importProfile"
 	ActionUpdate        Action = "update"
+	ActionModOverride   Action = "modOverride"
 )
 
 type Progress struct {
-	Action Action                  `json:"action"`
-	Item   ProgressItem            `json:"item"`
-	Tasks  map[string]ProgressTask `json:"tasks"`
+	Installation string                  `json:"installation"`
+	Action       Action                  `json:"action"`
+	Item         ProgressItem            `json:"item"`
+	Tasks        map[string]ProgressTask `json:"tasks"`
+	// Queued is set while the operation waits for the game of the installation to exit
+	Queued bool `json:"queued,omitempty"`
 }
 
 type ProgressItem struct {
@@ -61,11 +65,12 @@ func newItem(name, version string) ProgressItem {
 	}
 }
 
-func newProgress(action Action, item ProgressItem) *Progress {
+func newProgress(installation string, action Action, item ProgressItem) *Progress {
 	return &Progress{
-		Action: action,
-		Item:   item,
-		Tasks:  make(map[string]ProgressTask),
+		Installation: installation,
+		Action:       action,
+		Item:         item,
+		Tasks:        make(map[string]ProgressTask),
 	}
 }
 
@@ -92,4 +97,5 @@ var AllActionTypes = []struct {
 	{ActionSelectProfile, "SELECT_PROFILE"},
 	{ActionImportProfile, "IMPORT_PROFILE"},
 	{ActionUpdate, "UPDATE"},
+	{ActionModOverride, "MOD_OVERRIDE"},
 }
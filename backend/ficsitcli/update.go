@@ -8,6 +8,8 @@ import (
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 	resolver "github.com/satisfactorymodding/ficsit-resolver"
 	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
 )
 
 type Update struct {
@@ -24,7 +26,7 @@ func (f *ficsitCLI) CheckForUpdates() ([]Update, error) {
 	}
 	l := slog.With(slog.String("task", "checkForUpdates"))
 
-	currentLockfile, err := selectedInstallation.LockFile(f.ficsitCli)
+	currentLockfile, err := selectedInstallation.LockFile(f.contextSnapshot())
 	if err != nil {
 		l.Error("failed to get current lockfile", slog.Any("error", err))
 		return nil, fmt.Errorf("failed to get current lockfile: %w", err)
@@ -34,7 +36,12 @@ func (f *ficsitCLI) CheckForUpdates() ([]Update, error) {
 		return nil, nil
 	}
 
-	profile := f.GetProfile(selectedInstallation.Profile)
+	ctx := f.installContext(selectedInstallation)
+	profileName := f.getInstallationProfile(selectedInstallation)
+	profile := ctx.Profiles.GetProfile(profileName)
+	if profile == nil {
+		return nil, fmt.Errorf("profile %s not found", profileName)
+	}
 
 	res := resolver.NewDependencyResolver(f.ficsitCli.Provider, viper.GetString("api-base"))
 
@@ -48,10 +55,16 @@ func (f *ficsitCLI) CheckForUpdates() ([]Update, error) {
 		Name: "Update temp",
 		Mods: make(map[string]cli.ProfileMod),
 	}
+	overrides := settings.GetInstallationSettings(selectedInstallation.Path).ModOverrides
 	for modReference, modData := range profile.Mods {
+		version := ">=0.0.0"
+		if overrides[modReference].Version != "" {
+			// Mods pinned for this installation stay pinned
+			version = modData.Version
+		}
 		updateProfile.Mods[modReference] = cli.ProfileMod{
 			Enabled: modData.Enabled,
-			Version: ">=0.0.0",
+			Version: version,
 		}
 	}
 	newLockfile, err := updateProfile.Resolve(res, nil, gameVersion)
@@ -82,31 +95,35 @@ func (f *ficsitCLI) CheckForUpdates() ([]Update, error) {
 }
 
 func (f *ficsitCLI) UpdateMods(mods []string) error {
-	return f.action(ActionUpdate, noItem, func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
-		selectedInstallation := f.GetSelectedInstall()
+	selectedInstallation := f.GetSelectedInstall()
 
-		if selectedInstallation == nil {
-			return fmt.Errorf("no installation selected")
-		}
+	if selectedInstallation == nil {
+		return fmt.Errorf("no installation selected")
+	}
 
-		profile := f.GetProfile(selectedInstallation.Profile)
-		for _, modReference := range mods {
-			if _, ok := profile.Mods[modReference]; !ok {
-				l.Warn("mod not found in profile", slog.String("mod", modReference))
-				continue
-			}
-			profile.Mods[modReference] = cli.ProfileMod{
-				Enabled: profile.Mods[modReference].Enabled,
-				Version: ">=0.0.0",
-			}
-		}
+	return f.updateMods(selectedInstallation, mods)
+}
 
-		err := f.ficsitCli.Profiles.Save()
+func (f *ficsitCLI) updateMods(installation *cli.Installation, mods []string) error {
+	return f.action(installation, ActionUpdate, noItem, func(l *slog.Logger, taskUpdates chan<- taskUpdate) error {
+		err := f.updateProfile(l, f.getInstallationProfile(installation), func(profile *cli.Profile) error {
+			for _, modReference := range mods {
+				if _, ok := profile.Mods[modReference]; !ok {
+					l.Warn("mod not found in profile", slog.String("mod", modReference))
+					continue
+				}
+				profile.Mods[modReference] = cli.ProfileMod{
+					Enabled: profile.Mods[modReference].Enabled,
+					Version: ">=0.0.0",
+				}
+			}
+			return nil
+		})
 		if err != nil {
-			l.Error("failed to save profile", slog.Any("error", err))
+			return err
 		}
 
-		err = selectedInstallation.UpdateMods(f.ficsitCli, mods)
+		err = installation.UpdateMods(f.installContext(installation), mods)
 		if err != nil {
 			l.Error("failed to update mods", slog.Any("error", err))
 			var solvingError resolver.DependencyResolverError
@@ -116,7 +133,7 @@ func (f *ficsitCLI) UpdateMods(mods []string) error {
 			return err //nolint:wrapcheck
 		}
 
-		err = f.validateInstall(selectedInstallation, taskUpdates)
+		err = f.validateInstall(installation, taskUpdates)
 
 		if err != nil {
 			l.Error("failed to validate installation", slog.Any("error", err))
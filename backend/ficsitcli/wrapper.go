@@ -4,15 +4,15 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
-	"time"
 
-	"github.com/mitchellh/go-ps"
+	"github.com/fsnotify/fsnotify"
 	"github.com/puzpuzpuz/xsync/v3"
 	"github.com/satisfactorymodding/ficsit-cli/cli"
 	"github.com/satisfactorymodding/ficsit-cli/cli/provider"
 	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
 
 	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/gameprocess"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
 )
@@ -21,8 +21,31 @@ type ficsitCLI struct {
 	ficsitCli            *cli.GlobalContext
 	installationMetadata *xsync.MapOf[string, installationMetadata]
 	installFindErrors    []error
-	isGameRunning        bool
-	actionMutex          sync.Mutex
+	// installFindErrorsLock guards installFindErrors, which the installations watcher replaces when it rescans
+	installFindErrorsLock sync.RWMutex
+	// installLocks holds a lock per installation path, so actions on different installations can run at the same time
+	installLocks *xsync.MapOf[string, *sync.Mutex]
+	// profilesLock guards the state shared by all installations: the profiles, and the installations list that is saved with them
+	profilesLock         sync.RWMutex
+	installationsWatcher *fsnotify.Watcher
+	// runningProcesses holds the game clients and servers that are running, by PID
+	runningProcesses     map[int]gameprocess.Process
+	runningProcessesLock sync.RWMutex
+	// ignoreRunningGame holds the installations whose mods can be changed while their game is running, until it exits.
+	// It is guarded by runningProcessesLock, like blockedInstallations, which is the blocked state last sent to the frontend.
+	ignoreRunningGame    map[string]bool
+	blockedInstallations []string
+	// queuedActions holds the operations on each installation that wait for its game to exit, in order
+	queuedActions     map[string][]*queuedAction
+	queuedActionsLock sync.Mutex
+	// gameVersionChanges holds the game updates noticed since SMM started, until they are dismissed or fixed
+	gameVersionChanges *xsync.MapOf[string, GameVersionChange]
+	// gameVersionChecks holds the game version each installation is being checked against, so it is only checked once
+	gameVersionChecks *xsync.MapOf[string, int]
+	// sessionProfileRestores holds the profile to switch each installation back to once its game exits, see LaunchWithProfile.
+	// An installation has an entry while a restoreProfileAfterExit goroutine owns it.
+	sessionProfileRestores     map[string]*sessionProfileRestore
+	sessionProfileRestoresLock sync.Mutex
 }
 
 var FicsitCLI *ficsitCLI
@@ -37,7 +60,16 @@ func Init() error {
 	}
 	ficsitCli.Provider.(*provider.MixedProvider).Offline = settings.Settings.Offline
 
-	FicsitCLI = &ficsitCLI{ficsitCli: ficsitCli, installationMetadata: xsync.NewMapOf[string, installationMetadata]()}
+	FicsitCLI = &ficsitCLI{
+		ficsitCli:              ficsitCli,
+		installationMetadata:   xsync.NewMapOf[string, installationMetadata](),
+		installLocks:           xsync.NewMapOf[string, *sync.Mutex](),
+		gameVersionChanges:     xsync.NewMapOf[string, GameVersionChange](),
+		gameVersionChecks:      xsync.NewMapOf[string, int](),
+		ignoreRunningGame:      make(map[string]bool),
+		queuedActions:          make(map[string][]*queuedAction),
+		sessionProfileRestores: make(map[string]*sessionProfileRestore),
+	}
 	err = FicsitCLI.initInstallations()
 	if err != nil {
 		return fmt.Errorf("failed to initialize installations: %w", err)
@@ -45,27 +77,6 @@ func Init() error {
 	return nil
 }
 
-func (f *ficsitCLI) StartGameRunningWatcher() {
-	gameRunningTicker := time.NewTicker(5 * time.Second)
-	go func() {
-		for range gameRunningTicker.C {
-			processes, err := ps.Processes()
-			if err != nil {
-				slog.Error("failed to get processes", slog.Any("error", err))
-				continue
-			}
-			f.isGameRunning = false
-			for _, process := range processes {
-				if process.Executable() == "FactoryGame-Win64-Shipping.exe" || process.Executable() == "FactoryGame-Win64-Shipping" {
-					f.isGameRunning = true
-					break
-				}
-			}
-			wailsRuntime.EventsEmit(appCommon.AppContext, "isGameRunning", f.isGameRunning)
-		}
-	}()
-}
-
 // GetProgress exists only to ensure the Progress type is exported to typescript. It returns nil
 func (f *ficsitCLI) GetProgress() *Progress {
 	return nil
@@ -90,12 +101,9 @@ func (f *ficsitCLI) EmitGlobals() {
 	}
 	wailsRuntime.EventsEmit(appCommon.AppContext, "installations", f.GetInstallations())
 	wailsRuntime.EventsEmit(appCommon.AppContext, "installationsMetadata", f.GetInstallationsMetadata())
+	wailsRuntime.EventsEmit(appCommon.AppContext, "installationLabels", f.GetInstallationLabels())
 	wailsRuntime.EventsEmit(appCommon.AppContext, "remoteServers", f.GetRemoteInstallations())
-	profileNames := make([]string, 0, len(f.ficsitCli.Profiles.Profiles))
-	for k := range f.ficsitCli.Profiles.Profiles {
-		profileNames = append(profileNames, k)
-	}
-	wailsRuntime.EventsEmit(appCommon.AppContext, "profiles", profileNames)
+	wailsRuntime.EventsEmit(appCommon.AppContext, "profiles", f.GetProfiles())
 
 	selectedInstallation := f.GetSelectedInstall()
 
@@ -104,8 +112,8 @@ func (f *ficsitCLI) EmitGlobals() {
 	}
 
 	wailsRuntime.EventsEmit(appCommon.AppContext, "selectedInstallation", selectedInstallation.Path)
-	wailsRuntime.EventsEmit(appCommon.AppContext, "selectedProfile", selectedInstallation.Profile)
-	wailsRuntime.EventsEmit(appCommon.AppContext, "modsEnabled", !selectedInstallation.Vanilla)
+	wailsRuntime.EventsEmit(appCommon.AppContext, "selectedProfile", f.getInstallationProfile(selectedInstallation))
+	wailsRuntime.EventsEmit(appCommon.AppContext, "modsEnabled", f.GetModsEnabled())
 }
 
 func (f *ficsitCLI) isValidInstall(path string) bool {
@@ -114,7 +122,7 @@ func (f *ficsitCLI) isValidInstall(path string) bool {
 }
 
 func (f *ficsitCLI) WipeMods(includeRemote bool) error {
-	for _, i := range f.ficsitCli.Installations.Installations {
+	for _, i := range f.getInstallationsSnapshot() {
 		if !includeRemote {
 			meta, ok := f.installationMetadata.Load(i.Path)
 			if !ok {
@@ -130,9 +138,13 @@ func (f *ficsitCLI) WipeMods(includeRemote bool) error {
 			}
 		}
 
+		if f.IsInstallationBlocked(i.Path) {
+			return fmt.Errorf("failed to wipe installation %s: %w", f.GetInstallationLabel(i.Path).Name, ErrGameRunning)
+		}
+
 		err := i.Wipe()
 		if err != nil {
-			return fmt.Errorf("failed to wipe installation %s: %w", i.Path, err)
+			return fmt.Errorf("failed to wipe installation %s: %w", f.GetInstallationLabel(i.Path).Name, err)
 		}
 	}
 	return nil
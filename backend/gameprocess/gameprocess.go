@@ -0,0 +1,101 @@
+package gameprocess
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mitchellh/go-ps"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/utils"
+)
+
+// Process is a running game client or dedicated server
+type Process struct {
+	PID int `json:"pid"`
+	// Executable is the process name, which may be cut short
+	Executable string `json:"executable"`
+	Server     bool   `json:"server"`
+	// Path is the path of the game executable, translated to a host path if the game runs in wine.
+	// It is empty if it could not be read.
+	Path string `json:"path,omitempty"`
+	// WorkingDir is empty if it could not be read
+	WorkingDir string `json:"workingDir,omitempty"`
+	// Installation is the path of the installation the process was started from, if it is known
+	Installation string `json:"installation,omitempty"`
+}
+
+// The process name can be cut short, such as to 15 characters on Linux, so only the start of the executable name is matched.
+// FactoryGame also covers the launcher specific executables, such as FactoryGameSteam-Win64-Shipping.exe
+const (
+	clientPrefix = "FactoryGame"
+	serverPrefix = "FactoryServer-"
+)
+
+// List returns the game clients and dedicated servers that are running
+func List() ([]Process, error) {
+	processes, err := ps.Processes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	gameProcesses := make([]Process, 0)
+	for _, process := range processes {
+		executable := process.Executable()
+		isClient := strings.HasPrefix(executable, clientPrefix)
+		isServer := strings.HasPrefix(executable, serverPrefix)
+		if !isClient && !isServer {
+			continue
+		}
+		gameProcesses = append(gameProcesses, Process{
+			PID:        process.Pid(),
+			Executable: executable,
+			Server:     isServer,
+		})
+	}
+	return gameProcesses, nil
+}
+
+// CanMatchInstallations returns whether the processes can be matched to installations on this platform
+func CanMatchInstallations() bool {
+	return canReadDetails
+}
+
+// ReadDetails fills in the path and working directory of the process, which is slower than listing processes
+func (p *Process) ReadDetails() {
+	p.Path, p.WorkingDir = processDetails(p.PID)
+}
+
+// MatchInstallation sets the installation of the process to the one of installPaths its executable or working directory is in
+func (p *Process) MatchInstallation(installPaths []string) {
+	candidates := make([]string, 0, 2)
+	for _, path := range []string{p.Path, p.WorkingDir} {
+		if path != "" {
+			candidates = append(candidates, canonicalPath(path))
+		}
+	}
+
+	bestMatch := ""
+	for _, installPath := range installPaths {
+		canonicalInstallPath := canonicalPath(installPath)
+		for _, candidate := range candidates {
+			// Nested installations are unlikely, but the deepest one is the right one
+			if utils.IsIn(canonicalInstallPath, candidate) && len(installPath) > len(bestMatch) {
+				bestMatch = installPath
+			}
+		}
+	}
+	p.Installation = bestMatch
+}
+
+// canonicalPath resolves symlinks, such as the wine dosdevices drives, so paths found in different ways can be compared
+func canonicalPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	path = filepath.Clean(path)
+	if runtime.GOOS == "windows" {
+		path = strings.ToLower(path)
+	}
+	return path
+}
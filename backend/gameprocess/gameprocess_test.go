@@ -0,0 +1,61 @@
+package gameprocess
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchInstallation(t *testing.T) {
+	root := t.TempDir()
+	install := filepath.Join(root, "Satisfactory")
+	nested := filepath.Join(install, "Servers", "Satisfactory")
+	other := filepath.Join(root, "SatisfactoryExperimental")
+	for _, dir := range []string{install, nested, other} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	installPaths := []string{install, nested, other}
+
+	tests := []struct {
+		name     string
+		process  Process
+		expected string
+	}{
+		{
+			name:     "executable",
+			process:  Process{Path: filepath.Join(install, "FactoryGame", "Binaries", "Win64", "FactoryGame-Win64-Shipping.exe")},
+			expected: install,
+		},
+		{
+			name:     "working directory",
+			process:  Process{WorkingDir: other},
+			expected: other,
+		},
+		{
+			name:     "nested installation",
+			process:  Process{Path: filepath.Join(nested, "FactoryServer.sh")},
+			expected: nested,
+		},
+		{
+			name:     "sibling with a common prefix",
+			process:  Process{Path: filepath.Join(root, "Satisfactory2", "FactoryGame.exe")},
+			expected: "",
+		},
+		{
+			name:     "unknown path",
+			process:  Process{},
+			expected: "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			process := test.process
+			process.MatchInstallation(installPaths)
+			if process.Installation != test.expected {
+				t.Errorf("Installation = %q, expected %q", process.Installation, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package gameprocess
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+const canReadDetails = true
+
+func processDetails(pid int) (string, string) {
+	procPath := filepath.Join("/proc", fmt.Sprint(pid))
+
+	path, _ := os.Readlink(filepath.Join(procPath, "exe"))
+	workingDir, _ := os.Readlink(filepath.Join(procPath, "cwd"))
+
+	cmdline, err := os.ReadFile(filepath.Join(procPath, "cmdline"))
+	if err != nil {
+		return path, workingDir
+	}
+	environ, _ := os.ReadFile(filepath.Join(procPath, "environ"))
+	if winePath, ok := wineExecutablePath(cmdline, parseEnviron(environ)); ok {
+		path = winePath
+	}
+
+	return path, workingDir
+}
+
+// wineExecutablePath returns the host path of the game run by a wine or Proton process.
+// The executable of such a process is the wine loader, and the game is its first argument.
+func wineExecutablePath(cmdline []byte, env map[string]string) (string, bool) {
+	args := bytes.Split(cmdline, []byte{0})
+	if len(args) == 0 || !isWindowsPath(string(args[0])) {
+		return "", false
+	}
+	return common.WinePathProcessor(winePrefix(env))(string(args[0])), true
+}
+
+func isWindowsPath(path string) bool {
+	return len(path) >= 3 && path[1] == ':' && (path[2] == '\\' || path[2] == '/')
+}
+
+// parseEnviron reads the null separated variables of /proc/<pid>/environ
+func parseEnviron(environ []byte) map[string]string {
+	env := make(map[string]string)
+	for _, variable := range bytes.Split(environ, []byte{0}) {
+		key, value, ok := bytes.Cut(variable, []byte{'='})
+		if ok {
+			env[string(key)] = string(value)
+		}
+	}
+	return env
+}
+
+// winePrefix returns the wine prefix a process runs in, from its environment
+func winePrefix(env map[string]string) string {
+	if prefix := env["WINEPREFIX"]; prefix != "" {
+		return prefix
+	}
+	// Proton keeps the prefix in the compatdata directory of the game
+	if compatData := env["STEAM_COMPAT_DATA_PATH"]; compatData != "" {
+		return filepath.Join(compatData, "pfx")
+	}
+	if home := env["HOME"]; home != "" {
+		return filepath.Join(home, ".wine")
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".wine")
+}
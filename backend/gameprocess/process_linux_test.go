@@ -0,0 +1,115 @@
+package gameprocess
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWinePrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "wine prefix",
+			env:      map[string]string{"WINEPREFIX": "/home/user/Games/epic", "STEAM_COMPAT_DATA_PATH": "/unused", "HOME": "/home/user"},
+			expected: "/home/user/Games/epic",
+		},
+		{
+			name:     "proton",
+			env:      map[string]string{"STEAM_COMPAT_DATA_PATH": "/steam/steamapps/compatdata/526870", "HOME": "/home/user"},
+			expected: "/steam/steamapps/compatdata/526870/pfx",
+		},
+		{
+			name:     "default prefix",
+			env:      map[string]string{"HOME": "/home/user"},
+			expected: "/home/user/.wine",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if prefix := winePrefix(test.env); prefix != test.expected {
+				t.Errorf("winePrefix() = %q, expected %q", prefix, test.expected)
+			}
+		})
+	}
+}
+
+func TestParseEnviron(t *testing.T) {
+	env := parseEnviron([]byte("HOME=/home/user\x00WINEDLLOVERRIDES=a=b\x00INVALID\x00"))
+	if len(env) != 2 || env["HOME"] != "/home/user" || env["WINEDLLOVERRIDES"] != "a=b" {
+		t.Errorf("unexpected environment %v", env)
+	}
+}
+
+func TestWineExecutablePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		cmdline  string
+		expected string
+		ok       bool
+	}{
+		{
+			name:     "backslashes",
+			cmdline:  "C:\\Program Files\\Epic Games\\SatisfactoryEarlyAccess\\FactoryGame.exe\x00-EpicPortal\x00",
+			expected: "/prefix/dosdevices/c:/Program Files/Epic Games/SatisfactoryEarlyAccess/FactoryGame.exe",
+			ok:       true,
+		},
+		{
+			name:     "forward slashes",
+			cmdline:  "Z:/games/Satisfactory/FactoryGame.exe\x00",
+			expected: "/prefix/dosdevices/z:/games/Satisfactory/FactoryGame.exe",
+			ok:       true,
+		},
+		{
+			name:    "native process",
+			cmdline: "/games/Satisfactory/FactoryServer.sh\x00-log\x00",
+		},
+		{
+			name: "empty command line",
+		},
+	}
+	env := map[string]string{"WINEPREFIX": "/prefix"}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path, ok := wineExecutablePath([]byte(test.cmdline), env)
+			if path != test.expected || ok != test.ok {
+				t.Errorf("wineExecutablePath() = %q, %v, expected %q, %v", path, ok, test.expected, test.ok)
+			}
+		})
+	}
+}
+
+// A game running in wine is found through the dosdevices drive links of its prefix
+func TestMatchWineInstallation(t *testing.T) {
+	root := t.TempDir()
+	install := filepath.Join(root, "games", "Satisfactory")
+	if err := os.MkdirAll(install, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	executable := filepath.Join(install, "FactoryGame.exe")
+	if err := os.WriteFile(executable, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	prefix := filepath.Join(root, "prefix")
+	if err := os.MkdirAll(filepath.Join(prefix, "dosdevices"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(root, filepath.Join(prefix, "dosdevices", "d:")); err != nil {
+		t.Fatal(err)
+	}
+
+	path, ok := wineExecutablePath([]byte("D:\\games\\Satisfactory\\FactoryGame.exe\x00"), map[string]string{"WINEPREFIX": prefix})
+	if !ok || !strings.HasPrefix(path, filepath.Join(prefix, "dosdevices")) {
+		t.Fatalf("wineExecutablePath() = %q, %v, expected a path in the prefix", path, ok)
+	}
+
+	process := Process{Path: path}
+	process.MatchInstallation([]string{install})
+	if process.Installation != install {
+		t.Errorf("Installation = %q, expected %q", process.Installation, install)
+	}
+}
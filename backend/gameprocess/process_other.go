@@ -0,0 +1,10 @@
+//go:build !linux && !windows
+
+package gameprocess
+
+const canReadDetails = false
+
+// processDetails is not supported on this platform, so processes can't be matched to installations
+func processDetails(_ int) (string, string) {
+	return "", ""
+}
@@ -0,0 +1,23 @@
+package gameprocess
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+const canReadDetails = true
+
+func processDetails(pid int) (string, string) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return "", ""
+	}
+	defer windows.CloseHandle(handle) //nolint:errcheck
+
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	size := uint32(len(buf))
+	if err := windows.QueryFullProcessImageName(handle, 0, &buf[0], &size); err != nil {
+		return "", ""
+	}
+	// Reading the working directory of another process is not supported on Windows
+	return windows.UTF16ToString(buf[:size]), ""
+}
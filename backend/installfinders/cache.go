@@ -0,0 +1,84 @@
+package installfinders
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/utils"
+)
+
+// finderCache holds the installations found by each launcher in the last scan that finished
+type finderCache map[string][]*common.Installation
+
+func cacheFile() string {
+	return filepath.Join(viper.GetString("smm-cache-dir"), "installations-cache.json")
+}
+
+// cacheLock guards the cache file, which finders that finish after their timeout update in the background
+var cacheLock sync.Mutex
+
+func loadCache() finderCache {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	return readCache()
+}
+
+// updateCache applies change to the cache file
+func updateCache(change func(finderCache)) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+	cache := readCache()
+	change(cache)
+	writeCache(cache)
+}
+
+func readCache() finderCache {
+	cache := make(finderCache)
+	data, err := os.ReadFile(cacheFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			slog.Warn("failed to read installations cache", slog.Any("error", err))
+		}
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		slog.Warn("failed to parse installations cache", slog.Any("error", err))
+		return make(finderCache)
+	}
+	return cache
+}
+
+func writeCache(cache finderCache) {
+	data, err := utils.JSONMarshal(cache, 2)
+	if err != nil {
+		slog.Warn("failed to marshal installations cache", slog.Any("error", err))
+		return
+	}
+	if err := os.WriteFile(cacheFile(), data, 0o644); err != nil {
+		slog.Warn("failed to write installations cache", slog.Any("error", err))
+	}
+}
+
+// validInstallations returns the cached installations of the launcher that are still there,
+// with their version read again, since that is quick compared to asking the launcher
+func (c finderCache) validInstallations(launcher string) []*common.Installation {
+	installs := make([]*common.Installation, 0, len(c[launcher]))
+	for _, cached := range c[launcher] {
+		installType, version, err := common.GetGameInfo(cached.Path)
+		if err != nil {
+			continue
+		}
+		install := *cached
+		install.Type = installType
+		install.Version = version.Changelist
+		install.GameVersion = &version
+		installs = append(installs, &install)
+	}
+	return installs
+}
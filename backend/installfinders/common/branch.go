@@ -0,0 +1,33 @@
+package common
+
+// steamBetaKeyBranches are the steam beta keys of the known branches. An empty key means the default branch.
+var steamBetaKeyBranches = map[string]GameBranch{
+	"":             BranchEarlyAccess,
+	"public":       BranchEarlyAccess,
+	"experimental": BranchExperimental,
+}
+
+// BranchFromSteamBetaKey returns the branch of the steam beta key in an app manifest's UserConfig.
+// Other beta keys, such as ones for testing or for older versions, are BranchUnknown,
+// and the installation should record the key itself in BranchKey.
+func BranchFromSteamBetaKey(betakey string) (GameBranch, bool) {
+	if branch, ok := steamBetaKeyBranches[betakey]; ok {
+		return branch, true
+	}
+	return BranchUnknown, false
+}
+
+// versionFileBranches are the BranchName values in game version files that only one branch uses.
+// Release names such as ++FactoryGame+rel-main-u8 are not listed, since experimental builds use the same name
+// as early access ones, so their branch can only be detected from the steam beta key.
+var versionFileBranches = map[string]GameBranch{}
+
+// BranchFromVersionFile returns the branch of the BranchName in a game version file.
+// Names that are not known, or are shared by several branches, are BranchUnknown,
+// and the installation should record the name itself in BranchKey.
+func BranchFromVersionFile(branchName string) (GameBranch, bool) {
+	if branch, ok := versionFileBranches[branchName]; ok {
+		return branch, true
+	}
+	return BranchUnknown, false
+}
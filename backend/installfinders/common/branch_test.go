@@ -0,0 +1,48 @@
+package common
+
+import (
+	"testing"
+)
+
+func TestBranchFromSteamBetaKey(t *testing.T) {
+	tests := []struct {
+		betakey string
+		branch  GameBranch
+		known   bool
+	}{
+		{"", BranchEarlyAccess, true},
+		{"public", BranchEarlyAccess, true},
+		{"experimental", BranchExperimental, true},
+		{"Experimental", BranchUnknown, false},
+		{"experimental-old", BranchUnknown, false},
+		{"playtest", BranchUnknown, false},
+	}
+	for _, test := range tests {
+		branch, known := BranchFromSteamBetaKey(test.betakey)
+		if branch != test.branch || known != test.known {
+			t.Errorf("BranchFromSteamBetaKey(%q) = %s, %t, expected %s, %t", test.betakey, branch, known, test.branch, test.known)
+		}
+	}
+}
+
+func TestBranchFromVersionFile(t *testing.T) {
+	tests := []struct {
+		branchName string
+		branch     GameBranch
+		known      bool
+	}{
+		{"++FactoryGame+rel-main-u8", BranchUnknown, false},
+		{"", BranchUnknown, false},
+		{"++FactoryGame+rel-main-u8-exp", BranchUnknown, false},
+		{"++FactoryGame+dev-main", BranchUnknown, false},
+		{"++FactoryGame+release-executable", BranchUnknown, false},
+		{"++FactoryGame+experimental", BranchUnknown, false},
+		{"++factorygame+rel-main-u8", BranchUnknown, false},
+	}
+	for _, test := range tests {
+		branch, known := BranchFromVersionFile(test.branchName)
+		if branch != test.branch || known != test.known {
+			t.Errorf("BranchFromVersionFile(%q) = %s, %t, expected %s, %t", test.branchName, branch, known, test.branch, test.known)
+		}
+	}
+}
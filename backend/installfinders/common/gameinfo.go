@@ -55,7 +55,37 @@ type GameVersionFile struct {
 	BuildID              string `json:"BuildId"`
 }
 
-func GetGameInfo(path string) (InstallType, int, error) {
+// ServerVersionFilePaths returns the paths of the version files a server may have, relative to its directory
+func ServerVersionFilePaths() []string {
+	paths := make([]string, 0, len(gameInfo))
+	for _, info := range gameInfo {
+		if info.installType == InstallTypeWindowsClient {
+			continue
+		}
+		paths = append(paths, filepath.ToSlash(info.versionPath))
+	}
+	return paths
+}
+
+// GameVersion is the version of a game installation, as read from its version file
+type GameVersion struct {
+	Changelist int `json:"changelist"`
+	// Version is the engine version, as major.minor.patch
+	Version    string `json:"version"`
+	BranchName string `json:"branchName"`
+	BuildID    string `json:"buildId"`
+}
+
+func (v GameVersionFile) GameVersion() GameVersion {
+	return GameVersion{
+		Changelist: v.Changelist,
+		Version:    fmt.Sprintf("%d.%d.%d", v.MajorVersion, v.MinorVersion, v.PatchVersion),
+		BranchName: v.BranchName,
+		BuildID:    v.BuildID,
+	}
+}
+
+func GetGameInfo(path string) (InstallType, GameVersion, error) {
 	for _, info := range gameInfo {
 		executablePath := filepath.Join(path, info.executable)
 		if _, err := os.Stat(executablePath); os.IsNotExist(err) {
@@ -65,20 +95,22 @@ func GetGameInfo(path string) (InstallType, int, error) {
 
 		versionFilePath := filepath.Join(path, info.versionPath)
 		if _, err := os.Stat(versionFilePath); os.IsNotExist(err) {
-			return InstallTypeWindowsClient, 0, fmt.Errorf("failed to get game info")
+			// The same executable may have a version file with a different name, depending on the game version
+			slog.Debug("version file not found", slog.String("path", versionFilePath))
+			continue
 		}
 
 		versionFile, err := os.ReadFile(versionFilePath)
 		if err != nil {
-			return InstallTypeWindowsClient, 0, fmt.Errorf("failed to read version file %s: %w", versionFilePath, err)
+			return InstallTypeWindowsClient, GameVersion{}, fmt.Errorf("failed to read version file %s: %w", versionFilePath, err)
 		}
 
 		var versionData GameVersionFile
 		if err := json.Unmarshal(versionFile, &versionData); err != nil {
-			return InstallTypeWindowsClient, 0, fmt.Errorf("failed to parse version file %s: %w", versionFilePath, err)
+			return InstallTypeWindowsClient, GameVersion{}, fmt.Errorf("failed to parse version file %s: %w", versionFilePath, err)
 		}
 
-		return info.installType, versionData.Changelist, nil
+		return info.installType, versionData.GameVersion(), nil
 	}
-	return InstallTypeWindowsClient, 0, fmt.Errorf("failed to get game info")
+	return InstallTypeWindowsClient, GameVersion{}, fmt.Errorf("failed to get game info")
 }
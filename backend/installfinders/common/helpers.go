@@ -20,19 +20,39 @@ func FindAll(finders ...InstallFinderFunc) ([]*Installation, []error) {
 	var errors []error
 	for _, finder := range finders {
 		foundInstalls, foundErrors := finder()
-		for _, install := range foundInstalls {
-			existing := false
-			for i := range installs {
-				if OsPathEqual(installs[i].Path, install.Path) {
-					existing = true
-					break
-				}
-			}
-			if !existing {
-				installs = append(installs, install)
-			}
-		}
+		installs = MergeInstallations(installs, foundInstalls)
 		errors = append(errors, foundErrors...)
 	}
 	return installs, errors
 }
+
+// MergeInstallations adds the found installations that are not already in installs.
+// If one is, the service managing it is added to the existing installation, if that does not have one,
+// since an installation found from its files is usually the one a service runs.
+func MergeInstallations(installs []*Installation, found []*Installation) []*Installation {
+	for _, install := range found {
+		existing := false
+		for i := range installs {
+			if OsPathEqual(installs[i].Path, install.Path) {
+				existing = true
+				mergeService(installs[i], install)
+				break
+			}
+		}
+		if !existing {
+			installs = append(installs, install)
+		}
+	}
+	return installs
+}
+
+func mergeService(existing *Installation, found *Installation) {
+	if existing.SystemdUnit == "" {
+		existing.SystemdUnit = found.SystemdUnit
+	}
+	if len(existing.StopPath) == 0 && len(found.StopPath) > 0 {
+		// The server has to be started the same way, so that it can be stopped
+		existing.LaunchPath = found.LaunchPath
+		existing.StopPath = found.StopPath
+	}
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeInstallationsAddsService(t *testing.T) {
+	steamCMD := &Installation{
+		Path:       "/srv/satisfactory",
+		Launcher:   "SteamCMD",
+		LaunchPath: []string{"/srv/satisfactory/FactoryServer.sh"},
+	}
+	systemd := &Installation{
+		Path:        "/srv/satisfactory/",
+		Launcher:    "systemd - satisfactory",
+		LaunchPath:  []string{"systemctl", "start", "satisfactory.service"},
+		StopPath:    []string{"systemctl", "stop", "satisfactory.service"},
+		SystemdUnit: "satisfactory.service",
+	}
+	other := &Installation{Path: "/srv/other"}
+
+	installs := MergeInstallations([]*Installation{steamCMD}, []*Installation{systemd, other})
+
+	if len(installs) != 2 || installs[0] != steamCMD || installs[1] != other {
+		t.Fatalf("unexpected installations %v", installs)
+	}
+	if steamCMD.Launcher != "SteamCMD" {
+		t.Errorf("Launcher = %q, expected the first launcher to be kept", steamCMD.Launcher)
+	}
+	if steamCMD.SystemdUnit != systemd.SystemdUnit {
+		t.Errorf("SystemdUnit = %q, expected %q", steamCMD.SystemdUnit, systemd.SystemdUnit)
+	}
+	if !slices.Equal(steamCMD.LaunchPath, systemd.LaunchPath) || !slices.Equal(steamCMD.StopPath, systemd.StopPath) {
+		t.Errorf("LaunchPath, StopPath = %q, %q, expected the ones of the service", steamCMD.LaunchPath, steamCMD.StopPath)
+	}
+}
+
+func TestMergeInstallationsKeepsExistingService(t *testing.T) {
+	linuxGSM := &Installation{
+		Path:       "/home/sfserver/serverfiles",
+		LaunchPath: []string{"/home/sfserver/sfserver", "start"},
+		StopPath:   []string{"/home/sfserver/sfserver", "stop"},
+	}
+	systemd := &Installation{
+		Path:        "/home/sfserver/serverfiles",
+		LaunchPath:  []string{"systemctl", "start", "sfserver.service"},
+		StopPath:    []string{"systemctl", "stop", "sfserver.service"},
+		SystemdUnit: "sfserver.service",
+	}
+
+	MergeInstallations([]*Installation{linuxGSM}, []*Installation{systemd})
+
+	if linuxGSM.StopPath[0] != "/home/sfserver/sfserver" {
+		t.Errorf("StopPath = %q, expected the LinuxGSM one to be kept", linuxGSM.StopPath)
+	}
+	if linuxGSM.SystemdUnit != "sfserver.service" {
+		t.Errorf("SystemdUnit = %q, expected sfserver.service", linuxGSM.SystemdUnit)
+	}
+}
@@ -5,6 +5,8 @@ type GameBranch string
 var (
 	BranchEarlyAccess  GameBranch = "Early Access"
 	BranchExperimental GameBranch = "Experimental"
+	// BranchUnknown is used when the branch could not be detected, such as for some remote servers
+	BranchUnknown GameBranch = "Unknown"
 )
 
 type InstallType string
@@ -22,6 +24,18 @@ var (
 	LocationTypeRemote LocationType = "Remote"
 )
 
+// LaunchType is how the launch command of an installation starts the game, which decides which launch options apply to it
+type LaunchType string
+
+var (
+	// LaunchTypeDirect runs the game, or a script or launcher that runs it as a child, so all launch options apply
+	LaunchTypeDirect LaunchType = ""
+	// LaunchTypeSteamURL asks Steam to start the game through its URL, so only arguments can be passed on, in the URL
+	LaunchTypeSteamURL LaunchType = "SteamURL"
+	// LaunchTypeHandoff asks another program to start the game, such as the Epic launcher or systemd, so no launch options apply
+	LaunchTypeHandoff LaunchType = "Handoff"
+)
+
 type Installation struct {
 	Path       string       `json:"path"`
 	Version    int          `json:"version"`
@@ -30,6 +44,16 @@ type Installation struct {
 	Branch     GameBranch   `json:"branch"`
 	Launcher   string       `json:"launcher"`
 	LaunchPath []string     `json:"launchPath"`
+	LaunchType LaunchType   `json:"launchType,omitempty"`
+	// StopPath is the command that stops the server, for servers managed by a tool that runs them in the background
+	StopPath []string `json:"stopPath,omitempty"`
+	// SystemdUnit is the name of the systemd service that runs the server, if any
+	SystemdUnit string `json:"systemdUnit,omitempty"`
+	// BranchKey is what Branch was detected from, such as the steam beta key or Epic app name, even if it is not a known branch
+	BranchKey string `json:"branchKey,omitempty"`
+	// GameVersion is the full version of the game, of which Version is only the changelist.
+	// It is nil if the version file of a remote server could not be read.
+	GameVersion *GameVersion `json:"gameVersion,omitempty"`
 }
 
 type InstallFindError struct {
@@ -62,6 +86,7 @@ var AllBranches = []struct {
 }{
 	{BranchEarlyAccess, "EARLY_ACCESS"},
 	{BranchExperimental, "EXPERIMENTAL"},
+	{BranchUnknown, "UNKNOWN"},
 }
 
 var AllLocationTypes = []struct {
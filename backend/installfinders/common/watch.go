@@ -0,0 +1,64 @@
+package common
+
+import (
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"golang.org/x/exp/maps"
+)
+
+// ManifestMatcher reports whether a change to the file at path can change the installations a finder finds.
+// The file may not exist anymore when it was removed.
+type ManifestMatcher func(path string) bool
+
+var (
+	// manifestDirs holds the matchers of each directory by the name of their finder
+	manifestDirs     = make(map[string]map[string]ManifestMatcher)
+	manifestDirsLock sync.Mutex
+)
+
+// AddManifestDir records a directory the finder reads its launcher's manifests from,
+// so that installations can be found again when the launcher changes the manifests matches accepts.
+// The directory does not need to exist yet, so that installing the launcher is noticed too.
+// Finders add their directories on every scan, which replaces the matcher added before under the same finder name.
+func AddManifestDir(dir string, finder string, matches ManifestMatcher) {
+	manifestDirsLock.Lock()
+	defer manifestDirsLock.Unlock()
+	dir = filepath.Clean(dir)
+	if manifestDirs[dir] == nil {
+		manifestDirs[dir] = make(map[string]ManifestMatcher)
+	}
+	manifestDirs[dir][finder] = matches
+}
+
+func GetManifestDirs() []string {
+	manifestDirsLock.Lock()
+	defer manifestDirsLock.Unlock()
+	dirs := make([]string, 0, len(manifestDirs))
+	for dir := range manifestDirs {
+		dirs = append(dirs, dir)
+	}
+	slices.Sort(dirs)
+	return dirs
+}
+
+// IsManifest returns whether path is a manifest that a finder that added its directory reads
+func IsManifest(path string) bool {
+	manifestDirsLock.Lock()
+	matchers := maps.Values(manifestDirs[filepath.Dir(filepath.Clean(path))])
+	manifestDirsLock.Unlock()
+	for _, matches := range matchers {
+		if matches(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ManifestNames matches the manifests with the given file names
+func ManifestNames(names ...string) ManifestMatcher {
+	return func(path string) bool {
+		return slices.Contains(names, filepath.Base(path))
+	}
+}
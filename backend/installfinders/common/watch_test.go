@@ -0,0 +1,29 @@
+package common
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsManifest(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "steamapps")
+	AddManifestDir(dir, "test", ManifestNames("libraryfolders.vdf", "appmanifest_526870.acf"))
+	// Adding the directory again replaces the matcher instead of adding another one
+	AddManifestDir(dir, "test", ManifestNames("appmanifest_526870.acf"))
+
+	tests := []struct {
+		path     string
+		manifest bool
+	}{
+		{filepath.Join(dir, "appmanifest_526870.acf"), true},
+		{filepath.Join(dir, "libraryfolders.vdf"), false},
+		{filepath.Join(dir, "appmanifest_730.acf"), false},
+		{filepath.Join(dir, "downloading", "appmanifest_526870.acf"), false},
+		{filepath.Join(filepath.Dir(dir), "appmanifest_526870.acf"), false},
+	}
+	for _, test := range tests {
+		if manifest := IsManifest(test.path); manifest != test.manifest {
+			t.Errorf("IsManifest(%q) = %t, expected %t", test.path, manifest, test.manifest)
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package installfinders
 
 import (
+	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/exp/maps"
 
@@ -12,10 +16,188 @@ import (
 	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/all" // register all launchers
 )
 
+// finderTimeout is how long a single launcher can take to find its installations,
+// after which its cached installations are used instead
+const finderTimeout = 10 * time.Second
+
+// cachedFinderTimeout replaces finderTimeout for launchers that have cached installations,
+// so a slow launcher does not hold up startup when there is something to show already
+const cachedFinderTimeout = time.Second
+
+type FinderDiagnostic struct {
+	Launcher      string   `json:"launcher"`
+	DurationMs    int64    `json:"durationMs"`
+	Installations int      `json:"installations"`
+	Errors        []string `json:"errors"`
+	// TimedOut is set when the launcher took too long, so the installations it found in the last scan that finished are used instead
+	TimedOut bool `json:"timedOut"`
+}
+
+type finderResult struct {
+	installs []*common.Installation
+	errors   []error
+}
+
+// pendingFind is a finder that is still running, so that a scan started before it finishes waits for it instead of running it again
+type pendingFind struct {
+	done   chan struct{}
+	result finderResult
+	// timedOut is set once a scan stopped waiting for it. Guarded by findsLock.
+	timedOut bool
+}
+
+var (
+	diagnostics     []FinderDiagnostic
+	diagnosticsLock sync.RWMutex
+
+	findsLock    sync.Mutex
+	pendingFinds = make(map[string]*pendingFind)
+	// lateResults holds the results of finders that finished after their timeout, until the next scan uses them
+	lateResults       = make(map[string]finderResult)
+	lateResultHandler func()
+)
+
+// OnLateResult sets the function called when a launcher that timed out finishes finding its installations.
+// The next FindInstallations returns them without asking the launcher again.
+func OnLateResult(handler func()) {
+	findsLock.Lock()
+	defer findsLock.Unlock()
+	lateResultHandler = handler
+}
+
+// GetDiagnostics returns how each launcher did in the last FindInstallations
+func GetDiagnostics() []FinderDiagnostic {
+	diagnosticsLock.RLock()
+	defer diagnosticsLock.RUnlock()
+	return slices.Clone(diagnostics)
+}
+
+// FindInstallations runs all launcher finders concurrently.
+// A finder that takes longer than its timeout is left running in the background,
+// and the installations it found in the last scan that finished are used instead, until it finishes.
 func FindInstallations() ([]*common.Installation, []error) {
 	registrations := launchers.GetInstallFinders()
 
-	slog.Debug("finding installations", slog.String("launchers", strings.Join(maps.Keys(registrations), ",")))
+	// The order decides which launcher an installation found by several of them belongs to, so keep it stable
+	ids := maps.Keys(registrations)
+	slices.Sort(ids)
+
+	slog.Debug("finding installations", slog.String("launchers", strings.Join(ids, ",")))
+
+	cache := loadCache()
+
+	results := make([]finderResult, len(ids))
+	finderDiagnostics := make([]FinderDiagnostic, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			results[i], finderDiagnostics[i] = runFinder(id, registrations[id], cache)
+		}(i, id)
+	}
+	wg.Wait()
+
+	installs := make([]*common.Installation, 0)
+	var findErrors []error
+	for i := range ids {
+		installs = common.MergeInstallations(installs, results[i].installs)
+		findErrors = append(findErrors, results[i].errors...)
+	}
+
+	updateCache(func(cache finderCache) {
+		for i, id := range ids {
+			if !finderDiagnostics[i].TimedOut {
+				cache[id] = results[i].installs
+			}
+		}
+	})
+
+	diagnosticsLock.Lock()
+	diagnostics = finderDiagnostics
+	diagnosticsLock.Unlock()
+
+	return installs, findErrors
+}
+
+func runFinder(id string, finder common.InstallFinderFunc, cache finderCache) (finderResult, FinderDiagnostic) {
+	start := time.Now()
+
+	timeout := finderTimeout
+	if _, ok := cache[id]; ok {
+		timeout = cachedFinderTimeout
+	}
+
+	find := startFinder(id, finder)
+
+	var result finderResult
+	diagnostic := FinderDiagnostic{Launcher: id}
+	select {
+	case <-find.done:
+		result = find.result
+	case <-time.After(timeout):
+		findsLock.Lock()
+		find.timedOut = true
+		findsLock.Unlock()
+		slog.Warn("launcher took too long to find installations, using cached installations", slog.String("launcher", id))
+		diagnostic.TimedOut = true
+		result = finderResult{
+			installs: cache.validInstallations(id),
+			errors:   []error{fmt.Errorf("%s took longer than %s to find installations", id, timeout)},
+		}
+	}
+
+	diagnostic.DurationMs = time.Since(start).Milliseconds()
+	diagnostic.Installations = len(result.installs)
+	diagnostic.Errors = make([]string, 0, len(result.errors))
+	for _, err := range result.errors {
+		diagnostic.Errors = append(diagnostic.Errors, err.Error())
+	}
+	return result, diagnostic
+}
+
+// startFinder runs the finder in the background, unless it is still running from a previous scan,
+// or finished after that scan timed out, in which case its result is used
+func startFinder(id string, finder common.InstallFinderFunc) *pendingFind {
+	findsLock.Lock()
+	defer findsLock.Unlock()
+
+	if find, ok := pendingFinds[id]; ok {
+		return find
+	}
+	find := &pendingFind{done: make(chan struct{})}
+	if result, ok := lateResults[id]; ok {
+		delete(lateResults, id)
+		find.result = result
+		close(find.done)
+		return find
+	}
+	pendingFinds[id] = find
+
+	go func() {
+		installs, errs := finder()
+		find.result = finderResult{installs: installs, errors: errs}
+		close(find.done)
+
+		findsLock.Lock()
+		delete(pendingFinds, id)
+		timedOut := find.timedOut
+		if timedOut {
+			lateResults[id] = find.result
+		}
+		handler := lateResultHandler
+		findsLock.Unlock()
 
-	return common.FindAll(maps.Values(registrations)...)
+		if !timedOut {
+			return
+		}
+		slog.Info("launcher finished finding installations after timing out", slog.String("launcher", id), slog.Int("installations", len(installs)))
+		updateCache(func(cache finderCache) {
+			cache[id] = installs
+		})
+		if handler != nil {
+			handler()
+		}
+	}()
+	return find
 }
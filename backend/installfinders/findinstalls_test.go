@@ -0,0 +1,55 @@
+package installfinders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+func TestRunFinderKeepsLateResult(t *testing.T) {
+	viper.Set("smm-cache-dir", t.TempDir())
+
+	const id = "slow"
+	updateCache(func(cache finderCache) {
+		cache[id] = []*common.Installation{}
+	})
+
+	release := make(chan struct{})
+	calls := 0
+	finder := func() ([]*common.Installation, []error) {
+		calls++
+		<-release
+		return []*common.Installation{{Path: "/games/SatisfactoryEarlyAccess"}}, nil
+	}
+
+	lateResult := make(chan struct{}, 1)
+	OnLateResult(func() { lateResult <- struct{}{} })
+	t.Cleanup(func() { OnLateResult(nil) })
+
+	_, diagnostic := runFinder(id, finder, loadCache())
+	if !diagnostic.TimedOut {
+		t.Fatalf("expected the finder to time out")
+	}
+
+	close(release)
+	select {
+	case <-lateResult:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("late result was not reported")
+	}
+
+	if cached := loadCache()[id]; len(cached) != 1 || cached[0].Path != "/games/SatisfactoryEarlyAccess" {
+		t.Fatalf("late result was not cached: %v", cached)
+	}
+
+	result, diagnostic := runFinder(id, finder, loadCache())
+	if diagnostic.TimedOut || len(result.installs) != 1 {
+		t.Fatalf("expected the late result to be used, got %+v", diagnostic)
+	}
+	if calls != 1 {
+		t.Fatalf("expected the finder to run once, ran %d times", calls)
+	}
+}
@@ -1,11 +1,15 @@
 package all
 
 import (
+	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/bottles"   // register bottles
 	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/crossover" // register crossover
 	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/epic"      // register epic
 	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/heroic"    // register heroic
 	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/legendary" // register legendary
+	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/linuxgsm"  // register linuxgsm
 	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/lutris"    // register lutris
 	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/steam"     // register steam
+	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/steamcmd"  // register steamcmd
+	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/systemd"   // register systemd
 	_ "github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/whisky"    // register whisky
 )
@@ -0,0 +1,107 @@
+package bottles
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/epic"
+)
+
+type Bottle struct {
+	Name       string `yaml:"Name"`
+	Path       string `yaml:"Path"`
+	CustomPath bool   `yaml:"Custom_Path"`
+}
+
+var epicLauncherPath = `C:\Program Files (x86)\Epic Games\Launcher\Portal\Binaries\Win32\EpicGamesLauncher.exe`
+
+func init() {
+	launchers.Add("Bottles", func() ([]*common.Installation, []error) {
+		dataHome := os.Getenv("XDG_DATA_HOME")
+		if dataHome == "" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, []error{fmt.Errorf("failed to get user home dir: %w", err)}
+			}
+			dataHome = filepath.Join(homeDir, ".local", "share")
+		}
+		return findInstallations(filepath.Join(dataHome, "bottles"), []string{"bottles-cli"})
+	})
+	launchers.Add("Bottles-flatpak", func() ([]*common.Installation, []error) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, []error{fmt.Errorf("failed to get user home dir: %w", err)}
+		}
+		return findInstallations(
+			filepath.Join(homeDir, ".var", "app", "com.usebottles.bottles", "data", "bottles"),
+			[]string{"flatpak", "run", "--command=bottles-cli", "com.usebottles.bottles"},
+		)
+	})
+}
+
+func findInstallations(bottlesDataPath string, bottlesCliCmd []string) ([]*common.Installation, []error) {
+	bottlesPath := filepath.Join(bottlesDataPath, "bottles")
+	if _, err := os.Stat(bottlesPath); os.IsNotExist(err) {
+		return nil, []error{fmt.Errorf("bottles not installed")}
+	}
+
+	entries, err := os.ReadDir(bottlesPath)
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to list bottles: %w", err)}
+	}
+
+	installs := make([]*common.Installation, 0)
+	var findErrors []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		bottleConfigPath := filepath.Join(bottlesPath, entry.Name(), "bottle.yml")
+		bottle, err := readBottle(bottleConfigPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				findErrors = append(findErrors, fmt.Errorf("failed to read bottle %s: %w", entry.Name(), err))
+			}
+			continue
+		}
+
+		winePrefix := filepath.Join(bottlesPath, entry.Name())
+		if bottle.CustomPath && bottle.Path != "" {
+			winePrefix = bottle.Path
+		}
+
+		currentInstalls, errs := epic.FindInstallationsWine(winePrefix, "Bottles - "+bottle.Name, func(appName string) []string {
+			// Without the launch URL, this would only open the Epic Games Launcher
+			return makeBottlesCmd(bottlesCliCmd, "run", "-b", bottle.Name, "-e", epicLauncherPath, "--args", epic.LaunchURL(appName))
+		})
+		installs = append(installs, currentInstalls...)
+		if errs != nil {
+			findErrors = append(findErrors, errs...)
+		}
+	}
+	return installs, findErrors
+}
+
+func readBottle(bottleConfigPath string) (*Bottle, error) {
+	data, err := os.ReadFile(bottleConfigPath)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	var bottle Bottle
+	if err := yaml.Unmarshal(data, &bottle); err != nil {
+		return nil, fmt.Errorf("failed to parse bottle.yml: %w", err)
+	}
+	if bottle.Name == "" {
+		bottle.Name = filepath.Base(filepath.Dir(bottleConfigPath))
+	}
+	return &bottle, nil
+}
+
+func makeBottlesCmd(bottlesCliCmd []string, args ...string) []string {
+	return append(append([]string{}, bottlesCliCmd...), args...)
+}
@@ -0,0 +1,273 @@
+package custom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/epic"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/steam"
+)
+
+type ManifestFormat string
+
+var (
+	// FormatSteam search roots are Steam directories, containing steamapps/libraryfolders.vdf
+	FormatSteam ManifestFormat = "steam"
+	// FormatEpic search roots are Epic manifest directories
+	FormatEpic ManifestFormat = "epic"
+	// FormatDirectory search roots are game directories, or directories containing game directories
+	FormatDirectory ManifestFormat = "directory"
+)
+
+// Definition describes a launcher that SMM does not know about, loaded from a JSON or TOML file
+type Definition struct {
+	Name   string         `json:"name" toml:"name"`
+	Format ManifestFormat `json:"format" toml:"format"`
+	// SearchRoots are searched according to the format. Environment variables and a leading ~ are expanded.
+	SearchRoots []string `json:"searchRoots" toml:"searchRoots"`
+	// WinePrefixes are searched like the search roots, which are then Windows paths inside each prefix.
+	// The default Steam or Epic location is used if there are no search roots. Not supported on Windows.
+	WinePrefixes []string `json:"winePrefixes" toml:"winePrefixes"`
+	// LaunchCommand is a list of templates, in which {{.Path}} is the installation path,
+	// and {{.App}} is the Steam launch URL or Epic app name
+	LaunchCommand []string `json:"launchCommand" toml:"launchCommand"`
+}
+
+type launchData struct {
+	Path string
+	App  string
+}
+
+type launcher struct {
+	Definition
+	launchCommand []*template.Template
+}
+
+// LoadDefinitions registers the launchers defined by the .json and .toml files in dir.
+// A definition that can't be loaded does not stop the others from being registered.
+func LoadDefinitions(dir string) []error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return []error{fmt.Errorf("failed to list launcher definitions: %w", err)}
+	}
+
+	var loadErrors []error
+	for _, entry := range entries {
+		if entry.IsDir() || !isDefinitionFile(entry.Name()) {
+			continue
+		}
+		definitionPath := filepath.Join(dir, entry.Name())
+		l, err := loadDefinition(definitionPath)
+		if err != nil {
+			loadErrors = append(loadErrors, fmt.Errorf("failed to load launcher definition %s: %w", entry.Name(), err))
+			continue
+		}
+		if _, ok := launchers.GetInstallFinders()[l.Name]; ok {
+			loadErrors = append(loadErrors, fmt.Errorf("failed to load launcher definition %s: launcher %s already exists", entry.Name(), l.Name))
+			continue
+		}
+		launchers.Add(l.Name, l.findInstallations)
+	}
+	return loadErrors
+}
+
+func isDefinitionFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".json" || ext == ".toml"
+}
+
+func loadDefinition(definitionPath string) (*launcher, error) {
+	unmarshal := json.Unmarshal
+	if strings.ToLower(filepath.Ext(definitionPath)) == ".toml" {
+		unmarshal = toml.Unmarshal
+	}
+
+	data, err := os.ReadFile(definitionPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var definition Definition
+	if err := unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	if definition.Name == "" {
+		return nil, fmt.Errorf("missing name")
+	}
+	switch definition.Format {
+	case FormatSteam, FormatEpic, FormatDirectory:
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected steam, epic or directory", definition.Format)
+	}
+	if len(definition.SearchRoots) == 0 && len(definition.WinePrefixes) == 0 {
+		return nil, fmt.Errorf("no search roots or wine prefixes")
+	}
+
+	l := &launcher{Definition: definition}
+	for _, arg := range definition.LaunchCommand {
+		t, err := template.New("").Option("missingkey=error").Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid launch command argument %q: %w", arg, err)
+		}
+		l.launchCommand = append(l.launchCommand, t)
+	}
+	return l, nil
+}
+
+func (l *launcher) findInstallations() ([]*common.Installation, []error) {
+	installs := make([]*common.Installation, 0)
+	var findErrors []error
+
+	if len(l.WinePrefixes) == 0 {
+		for _, root := range l.SearchRoots {
+			rootInstalls, rootErrors := l.findIn(expandPath(root), nil)
+			installs = append(installs, rootInstalls...)
+			findErrors = append(findErrors, rootErrors...)
+		}
+	}
+	for _, prefix := range l.WinePrefixes {
+		prefixInstalls, prefixErrors := l.findInWinePrefix(expandPath(prefix))
+		installs = append(installs, prefixInstalls...)
+		findErrors = append(findErrors, prefixErrors...)
+	}
+
+	for _, install := range installs {
+		// The finders put the Steam launch URL or Epic app name in the launch path, see appLaunchPath
+		data := launchData{Path: install.Path}
+		if len(install.LaunchPath) > 0 {
+			data.App = install.LaunchPath[0]
+		}
+		launchPath, err := l.renderLaunchCommand(data)
+		if err != nil {
+			// The installation is still usable, it just can't be launched by SMM
+			findErrors = append(findErrors, common.InstallFindError{
+				Path:  install.Path,
+				Inner: fmt.Errorf("%s: %w", l.Name, err),
+			})
+		}
+		install.LaunchPath = launchPath
+		// The launch command is the user's own, so launch options are applied to it as is,
+		// unless it still launches through the Steam URL, which the arguments have to go through
+		if install.LaunchType != common.LaunchTypeSteamURL || !slices.ContainsFunc(launchPath, isSteamLaunchURL) {
+			install.LaunchType = common.LaunchTypeDirect
+		}
+	}
+
+	return installs, findErrors
+}
+
+func (l *launcher) findIn(root string, processPath func(string) string) ([]*common.Installation, []error) {
+	switch l.Format {
+	case FormatSteam:
+		return steam.FindInstallationsSteam(root, l.Name, appLaunchPath, processPath)
+	case FormatEpic:
+		if processPath != nil {
+			root = processPath(root)
+		}
+		return epic.FindInstallationsEpic(root, l.Name, appLaunchPath, processPath)
+	default:
+		if processPath != nil {
+			root = processPath(root)
+		}
+		return l.findInDirectory(root)
+	}
+}
+
+func isSteamLaunchURL(arg string) bool {
+	return strings.Contains(arg, steam.LaunchURL)
+}
+
+// appLaunchPath keeps the app in the launch path, until the launch command template is rendered
+func appLaunchPath(app string) []string {
+	return []string{app}
+}
+
+func (l *launcher) findInDirectory(root string) ([]*common.Installation, []error) {
+	if install, err := l.directoryInstallation(root); err == nil {
+		return []*common.Installation{install}, nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to list %s: %w", root, err)}
+	}
+
+	installs := make([]*common.Installation, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		// Other directories next to the game are expected, so they are not reported as invalid installations
+		install, err := l.directoryInstallation(filepath.Join(root, entry.Name()))
+		if err != nil {
+			continue
+		}
+		installs = append(installs, install)
+	}
+	if len(installs) == 0 {
+		return nil, []error{fmt.Errorf("no installations found in %s", root)}
+	}
+	return installs, nil
+}
+
+func (l *launcher) directoryInstallation(path string) (*common.Installation, error) {
+	installType, version, err := common.GetGameInfo(path)
+	if err != nil {
+		return nil, common.InstallFindError{
+			Path:  path,
+			Inner: err,
+		}
+	}
+	// Directories have no launcher manifest, so the branch can only be read from the version file
+	branch, _ := common.BranchFromVersionFile(version.BranchName)
+	return &common.Installation{
+		Path:        filepath.Clean(path),
+		Version:     version.Changelist,
+		GameVersion: &version,
+		Type:        installType,
+		Location:    common.LocationTypeLocal,
+		Branch:      branch,
+		BranchKey:   version.BranchName,
+		Launcher:    l.Name,
+	}, nil
+}
+
+func (l *launcher) renderLaunchCommand(data launchData) ([]string, error) {
+	launchPath := make([]string, 0, len(l.launchCommand))
+	for _, t := range l.launchCommand {
+		var arg bytes.Buffer
+		if err := t.Execute(&arg, data); err != nil {
+			return nil, fmt.Errorf("failed to render launch command: %w", err)
+		}
+		launchPath = append(launchPath, arg.String())
+	}
+	if len(launchPath) > 0 && strings.TrimSpace(launchPath[0]) == "" {
+		// For example {{.App}} with the directory format, which has no app
+		return nil, fmt.Errorf("launch command %q renders to an empty program", l.LaunchCommand[0])
+	}
+	return launchPath, nil
+}
+
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(homeDir, path[1:])
+		}
+	}
+	return filepath.Clean(path)
+}
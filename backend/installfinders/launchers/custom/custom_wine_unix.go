@@ -0,0 +1,38 @@
+//go:build unix
+
+package custom
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/epic"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/steam"
+)
+
+var wineSteamPath = filepath.Join("c:", "Program Files (x86)", "Steam") // Will get run through processPath, so it will be added to the dosdevices path
+
+func (l *launcher) findInWinePrefix(winePrefix string) ([]*common.Installation, []error) {
+	processPath := common.WinePathProcessor(winePrefix)
+
+	if len(l.SearchRoots) == 0 {
+		switch l.Format {
+		case FormatSteam:
+			return steam.FindInstallationsSteam(wineSteamPath, l.Name, appLaunchPath, processPath)
+		case FormatEpic:
+			return epic.FindInstallationsEpic(epic.WineManifestsPath(winePrefix), l.Name, appLaunchPath, processPath)
+		default:
+			return nil, []error{fmt.Errorf("directory launchers need search roots to search in wine prefix %s", winePrefix)}
+		}
+	}
+
+	installs := make([]*common.Installation, 0)
+	var findErrors []error
+	for _, root := range l.SearchRoots {
+		rootInstalls, rootErrors := l.findIn(root, processPath)
+		installs = append(installs, rootInstalls...)
+		findErrors = append(findErrors, rootErrors...)
+	}
+	return installs, findErrors
+}
@@ -0,0 +1,11 @@
+package custom
+
+import (
+	"fmt"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+func (l *launcher) findInWinePrefix(winePrefix string) ([]*common.Installation, []error) {
+	return nil, []error{fmt.Errorf("wine prefixes are not supported on windows, skipping %s", winePrefix)}
+}
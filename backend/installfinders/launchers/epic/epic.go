@@ -32,19 +32,43 @@ var (
 	ExperimentalDedicatedServerAppName = "c509233193024c5f8124467d3aa36199"
 )
 
-func GetEpicBranch(appName string) (common.GameBranch, error) {
+// LaunchURL returns the URL that makes the Epic Games Launcher launch the app
+func LaunchURL(appName string) string {
+	return "com.epicgames.launcher://apps/" + appName + "?action=launch&silent=true"
+}
+
+// GetEpicBranch returns the branch of the Epic app name.
+// Other app names are BranchUnknown, and the installation should record the app name itself in BranchKey.
+func GetEpicBranch(appName string) (common.GameBranch, bool) {
 	switch appName {
 	case EarlyAccessAppName:
-		return common.BranchEarlyAccess, nil
+		return common.BranchEarlyAccess, true
 	case ExperimentalAppName:
-		return common.BranchExperimental, nil
+		return common.BranchExperimental, true
 	case EarlyAccessDedicatedServerAppName:
-		return common.BranchEarlyAccess, nil
+		return common.BranchEarlyAccess, true
 	case ExperimentalDedicatedServerAppName:
-		return common.BranchExperimental, nil
+		return common.BranchExperimental, true
 	default:
-		return "", fmt.Errorf("unknown branch for " + appName)
+		return common.BranchUnknown, false
+	}
+}
+
+// isManifest matches the Epic manifests of Satisfactory. Removed manifests can't be read anymore, so they all match.
+func isManifest(path string) bool {
+	if filepath.Ext(path) != ".item" {
+		return false
+	}
+	manifestData, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+	var epicManifest Manifest
+	if err := json.Unmarshal(manifestData, &epicManifest); err != nil {
+		// The launcher may still be writing it
+		return true
 	}
+	return epicManifest.CatalogNamespace == "crab"
 }
 
 func FindInstallationsEpic(epicManifestsPath string, launcher string, launchPath func(appName string) []string, processPath func(path string) string) ([]*common.Installation, []error) {
@@ -56,6 +80,7 @@ func FindInstallationsEpic(epicManifestsPath string, launcher string, launchPath
 		processPath = func(path string) string { return path }
 	}
 
+	common.AddManifestDir(epicManifestsPath, "epic", isManifest)
 	if _, err := os.Stat(epicManifestsPath); os.IsNotExist(err) {
 		return nil, []error{fmt.Errorf("epic is not installed")}
 	}
@@ -139,23 +164,19 @@ func FindInstallationsEpic(epicManifestsPath string, launcher string, launchPath
 			continue
 		}
 
-		branch, err := GetEpicBranch(epicManifest.MainGameAppName)
-		if err != nil {
-			findErrors = append(findErrors, common.InstallFindError{
-				Path:  installLocation,
-				Inner: err,
-			})
-			continue
-		}
+		branch, _ := GetEpicBranch(epicManifest.MainGameAppName)
 
 		installs = append(installs, &common.Installation{
-			Path:       filepath.Clean(installLocation),
-			Version:    version,
-			Type:       installType,
-			Location:   common.LocationTypeLocal,
-			Branch:     branch,
-			Launcher:   launcher,
-			LaunchPath: launchPath(epicManifest.MainGameAppName),
+			Path:        filepath.Clean(installLocation),
+			Version:     version.Changelist,
+			GameVersion: &version,
+			Type:        installType,
+			Location:    common.LocationTypeLocal,
+			Branch:      branch,
+			BranchKey:   epicManifest.MainGameAppName,
+			Launcher:    launcher,
+			LaunchPath:  launchPath(epicManifest.MainGameAppName),
+			LaunchType:  common.LaunchTypeHandoff,
 		})
 	}
 
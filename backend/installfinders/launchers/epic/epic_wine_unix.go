@@ -12,13 +12,19 @@ import (
 
 var epicWineManifestPath = filepath.Join("c:", "ProgramData", "Epic", "EpicGamesLauncher", "Data", "Manifests")
 
-func FindInstallationsWine(winePrefix string, launcher string, launchPath []string) ([]*common.Installation, []error) {
-	wineWindowsRoot := filepath.Join(winePrefix, "dosdevices")
-	epicManifestsPath := filepath.Join(wineWindowsRoot, epicWineManifestPath)
+// WineManifestsPath returns the path of the Epic manifests directory inside the wine prefix
+func WineManifestsPath(winePrefix string) string {
+	return filepath.Join(winePrefix, "dosdevices", epicWineManifestPath)
+}
+
+// FindInstallationsWine finds the Epic installations in the wine prefix.
+// launchPath returns the command that launches the Epic app inside the prefix, see LaunchURL.
+func FindInstallationsWine(winePrefix string, launcher string, launchPath func(appName string) []string) ([]*common.Installation, []error) {
+	epicManifestsPath := WineManifestsPath(winePrefix)
 
 	if _, err := os.Stat(epicManifestsPath); os.IsNotExist(err) {
 		return nil, []error{fmt.Errorf("Epic is not installed in " + winePrefix)}
 	}
 
-	return FindInstallationsEpic(epicManifestsPath, launcher, func(appName string) []string { return launchPath }, common.WinePathProcessor(winePrefix))
+	return FindInstallationsEpic(epicManifestsPath, launcher, launchPath, common.WinePathProcessor(winePrefix))
 }
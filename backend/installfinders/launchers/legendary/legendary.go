@@ -33,6 +33,7 @@ type Data = map[string]Game
 
 func FindInstallationsIn(legendaryDataPath string, launcher string) ([]*common.Installation, []error) {
 	legendaryInstalledPath := filepath.Join(legendaryDataPath, "installed.json")
+	common.AddManifestDir(legendaryDataPath, "legendary", common.ManifestNames("installed.json"))
 	if _, err := os.Stat(legendaryInstalledPath); os.IsNotExist(err) {
 		return nil, []error{fmt.Errorf("%s not installed", launcher)}
 	}
@@ -62,27 +63,22 @@ func FindInstallationsIn(legendaryDataPath string, launcher string) ([]*common.I
 			continue
 		}
 
-		branch, err := epic.GetEpicBranch(legendaryGame.AppName)
-		if err != nil {
-			findErrors = append(findErrors, common.InstallFindError{
-				Path:  legendaryGame.InstallPath,
-				Inner: err,
-			})
-			continue
-		}
+		branch, _ := epic.GetEpicBranch(legendaryGame.AppName)
 
 		var launchPath []string
 		if canLaunchLegendary {
 			launchPath = []string{"legendary", "launch", legendaryGame.AppName}
 		}
 		installs = append(installs, &common.Installation{
-			Path:       filepath.Clean(legendaryGame.InstallPath),
-			Version:    version,
-			Type:       installType,
-			Location:   common.LocationTypeLocal,
-			Branch:     branch,
-			Launcher:   launcher,
-			LaunchPath: launchPath,
+			Path:        filepath.Clean(legendaryGame.InstallPath),
+			Version:     version.Changelist,
+			GameVersion: &version,
+			Type:        installType,
+			Location:    common.LocationTypeLocal,
+			Branch:      branch,
+			BranchKey:   legendaryGame.AppName,
+			Launcher:    launcher,
+			LaunchPath:  launchPath,
 		})
 	}
 	return installs, findErrors
@@ -0,0 +1,83 @@
+package linuxgsm
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/steamcmd"
+)
+
+func init() {
+	launchers.Add("LinuxGSM", func() ([]*common.Installation, []error) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, []error{fmt.Errorf("failed to get user home dir: %w", err)}
+		}
+
+		// LinuxGSM is usually installed in the home directory of a user dedicated to the server
+		roots := []string{homeDir}
+		if homeEntries, err := os.ReadDir("/home"); err == nil {
+			for _, entry := range homeEntries {
+				root := filepath.Join("/home", entry.Name())
+				if entry.IsDir() && !common.OsPathEqual(root, homeDir) {
+					roots = append(roots, root)
+				}
+			}
+		}
+
+		installs := make([]*common.Installation, 0)
+		var findErrors []error
+		for _, root := range roots {
+			rootInstalls, rootErrors := findInstallations(root)
+			installs = append(installs, rootInstalls...)
+			findErrors = append(findErrors, rootErrors...)
+		}
+
+		if len(installs) == 0 && len(findErrors) == 0 {
+			return nil, []error{fmt.Errorf("linuxgsm not installed")}
+		}
+		return installs, findErrors
+	})
+}
+
+// findInstallations finds the LinuxGSM server in root, which has the game in serverfiles,
+// and a config directory in lgsm/config-lgsm for each of its server scripts
+func findInstallations(root string) ([]*common.Installation, []error) {
+	serverFilesPath := filepath.Join(root, "serverfiles")
+	if _, err := os.Stat(serverFilesPath); err != nil {
+		return nil, nil
+	}
+
+	configs, err := os.ReadDir(filepath.Join(root, "lgsm", "config-lgsm"))
+	if err != nil {
+		return nil, nil
+	}
+
+	for _, config := range configs {
+		if !config.IsDir() {
+			continue
+		}
+		scriptPath := filepath.Join(root, config.Name())
+		if _, err := os.Stat(scriptPath); err != nil {
+			continue
+		}
+
+		install, err := steamcmd.ServerInstallation(serverFilesPath, "LinuxGSM")
+		if err != nil {
+			return nil, []error{err}
+		}
+		install.LaunchPath = []string{scriptPath, "start"}
+		// The script starts the server in a tmux session, and exits
+		install.LaunchType = common.LaunchTypeHandoff
+		install.StopPath = []string{scriptPath, "stop"}
+		return []*common.Installation{install}, nil
+	}
+
+	return nil, []error{common.InstallFindError{
+		Path:  serverFilesPath,
+		Inner: fmt.Errorf("no linuxgsm server script found"),
+	}}
+}
@@ -1,9 +1,11 @@
 package lutris
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"time"
 
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers"
@@ -18,6 +20,8 @@ type Game struct {
 	Directory string `json:"directory"`
 }
 
+const lutrisTimeout = 10 * time.Second
+
 func init() {
 	launchers.Add("Lutris", func() ([]*common.Installation, []error) {
 		return findInstallations([]string{"lutris"}, "Lutris")
@@ -28,8 +32,12 @@ func init() {
 }
 
 func findInstallations(lutrisCmd []string, launcher string) ([]*common.Installation, []error) {
+	// lutris can hang, in which case it is killed instead of being left running after the finder times out
+	ctx, cancel := context.WithTimeout(context.Background(), lutrisTimeout)
+	defer cancel()
+
 	lutrisLjCmd := makeLutrisCmd(lutrisCmd, "-lj")
-	lutrisLj := exec.Command(lutrisLjCmd[0], lutrisLjCmd[1:]...)
+	lutrisLj := exec.CommandContext(ctx, lutrisLjCmd[0], lutrisLjCmd[1:]...)
 	outputBytes, err := lutrisLj.Output()
 	if err != nil {
 		return nil, []error{
@@ -47,7 +55,9 @@ func findInstallations(lutrisCmd []string, launcher string) ([]*common.Installat
 	installs := []*common.Installation{}
 	findErrors := []error{}
 	for _, lutrisGame := range lutrisGames {
-		currentInstalls, errs := epic.FindInstallationsWine(lutrisGame.Directory, launcher+" - "+lutrisGame.Name, makeLutrisCmd(lutrisCmd, "lutris:rungame/"+lutrisGame.Slug))
+		// Lutris launches the game the way it was set up, so the command does not depend on the Epic app
+		launchPath := makeLutrisCmd(lutrisCmd, "lutris:rungame/"+lutrisGame.Slug)
+		currentInstalls, errs := epic.FindInstallationsWine(lutrisGame.Directory, launcher+" - "+lutrisGame.Name, func(string) []string { return launchPath })
 		installs = append(installs, currentInstalls...)
 		if errs != nil {
 			findErrors = append(findErrors, errs...)
@@ -57,5 +67,5 @@ func findInstallations(lutrisCmd []string, launcher string) ([]*common.Installat
 }
 
 func makeLutrisCmd(lutrisCmd []string, args ...string) []string {
-	return append(lutrisCmd, args...)
+	return append(append([]string{}, lutrisCmd...), args...)
 }
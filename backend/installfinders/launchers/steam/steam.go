@@ -2,16 +2,66 @@ package steam
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/andygrunwald/vdf"
 
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
 )
 
-var manifests = []string{"appmanifest_526870.acf", "appmanifest_1690800.acf"}
+// DedicatedServerManifest is the app manifest of the dedicated server, which is also used by SteamCMD
+const DedicatedServerManifest = "appmanifest_1690800.acf"
+
+// LaunchURL makes Steam launch Satisfactory
+const LaunchURL = "steam://rungameid/526870"
+
+// LaunchURLWithArgs returns the URL that makes Steam launch Satisfactory with extra arguments.
+// Steam splits the arguments on spaces, so an argument can't contain one.
+func LaunchURLWithArgs(args []string) string {
+	return "steam://run/526870//" + url.PathEscape(strings.Join(args, " ")) + "/"
+}
+
+var manifests = []string{"appmanifest_526870.acf", DedicatedServerManifest}
+
+// isManifest matches the steam files that list the libraries and the Satisfactory installations in them
+var isManifest = common.ManifestNames(append([]string{"libraryfolders.vdf"}, manifests...)...)
+
+// AppManifest is the part of a steam app manifest SMM uses
+type AppManifest struct {
+	InstallDir string
+	BetaKey    string
+}
+
+func ReadAppManifest(manifestPath string) (*AppManifest, error) {
+	manifestF, err := os.Open(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest file %s: %w", manifestPath, err)
+	}
+	defer manifestF.Close()
+
+	parser := vdf.NewParser(manifestF)
+	manifest, err := parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest file %s: %w", manifestPath, err)
+	}
+
+	appState, ok := manifest["AppState"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to find AppState in manifest %s", manifestPath)
+	}
+
+	installDir, _ := appState["installdir"].(string)
+	userConfig, _ := appState["UserConfig"].(map[string]interface{})
+	betakey, _ := userConfig["betakey"].(string)
+	return &AppManifest{
+		InstallDir: installDir,
+		BetaKey:    betakey,
+	}, nil
+}
 
 func FindInstallationsSteam(steamPath string, launcher string, launchPath func(steamApp string) []string, processPath func(path string) string) ([]*common.Installation, []error) {
 	if launchPath == nil {
@@ -24,6 +74,7 @@ func FindInstallationsSteam(steamPath string, launcher string, launchPath func(s
 
 	steamAppsPath := filepath.Join(steamPath, "steamapps")
 	libraryFoldersManifestPath := processPath(filepath.Join(steamAppsPath, "libraryfolders.vdf"))
+	common.AddManifestDir(filepath.Dir(libraryFoldersManifestPath), "steam", isManifest)
 
 	libraryFoldersF, err := os.Open(libraryFoldersManifestPath)
 	if err != nil {
@@ -74,6 +125,7 @@ func FindInstallationsSteam(steamPath string, launcher string, launchPath func(s
 	var findErrors []error
 
 	for _, libraryFolder := range libraryFolders {
+		common.AddManifestDir(processPath(filepath.Join(libraryFolder, "steamapps")), "steam", isManifest)
 		for _, manifest := range manifests {
 			manifestPath := processPath(filepath.Join(libraryFolder, "steamapps", manifest))
 
@@ -81,25 +133,13 @@ func FindInstallationsSteam(steamPath string, launcher string, launchPath func(s
 				continue
 			}
 
-			manifestF, err := os.Open(manifestPath)
-			if err != nil {
-				findErrors = append(findErrors, fmt.Errorf("failed to open manifest file %s: %w", manifestPath, err))
-				continue
-			}
-
-			parser := vdf.NewParser(manifestF)
-			manifest, err := parser.Parse()
+			appManifest, err := ReadAppManifest(manifestPath)
 			if err != nil {
-				findErrors = append(findErrors, fmt.Errorf("failed to parse manifest file %s: %w", manifestPath, err))
-				continue
-			}
-
-			if _, ok := manifest["AppState"]; !ok {
-				findErrors = append(findErrors, fmt.Errorf("failed to find AppState in manifest %s", manifestPath))
+				findErrors = append(findErrors, err)
 				continue
 			}
 
-			fullInstallationPath := processPath(filepath.Join(libraryFolder, "steamapps", "common", manifest["AppState"].(map[string]interface{})["installdir"].(string)))
+			fullInstallationPath := processPath(filepath.Join(libraryFolder, "steamapps", "common", appManifest.InstallDir))
 
 			installType, version, err := common.GetGameInfo(fullInstallationPath)
 			if err != nil {
@@ -110,27 +150,19 @@ func FindInstallationsSteam(steamPath string, launcher string, launchPath func(s
 				continue
 			}
 
-			var branch common.GameBranch
-			userConfig := manifest["AppState"].(map[string]interface{})["UserConfig"].(map[string]interface{})
-			betakey, ok := userConfig["betakey"]
-			if !ok {
-				branch = common.BranchEarlyAccess
-			} else {
-				if betakey == "experimental" {
-					branch = common.BranchExperimental
-				} else {
-					findErrors = append(findErrors, fmt.Errorf("unknown beta key %s", betakey))
-				}
-			}
+			branch, _ := common.BranchFromSteamBetaKey(appManifest.BetaKey)
 
 			installs = append(installs, &common.Installation{
-				Path:       filepath.Clean(fullInstallationPath),
-				Version:    version,
-				Type:       installType,
-				Location:   common.LocationTypeLocal,
-				Branch:     branch,
-				Launcher:   launcher,
-				LaunchPath: launchPath(`steam://rungameid/526870`),
+				Path:        filepath.Clean(fullInstallationPath),
+				Version:     version.Changelist,
+				GameVersion: &version,
+				Type:        installType,
+				Location:    common.LocationTypeLocal,
+				Branch:      branch,
+				BranchKey:   appManifest.BetaKey,
+				Launcher:    launcher,
+				LaunchPath:  launchPath(LaunchURL),
+				LaunchType:  common.LaunchTypeSteamURL,
 			})
 		}
 	}
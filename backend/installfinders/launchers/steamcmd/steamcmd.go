@@ -0,0 +1,56 @@
+package steamcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/steam"
+)
+
+// ServerInstallation describes the Linux dedicated server installed in dir by SteamCMD with force_install_dir.
+// The branch is read from the app manifest SteamCMD keeps in dir, or from the version file if there is no manifest.
+func ServerInstallation(dir string, launcher string) (*common.Installation, error) {
+	installType, version, err := common.GetGameInfo(dir)
+	if err != nil {
+		return nil, common.InstallFindError{
+			Path:  dir,
+			Inner: err,
+		}
+	}
+	if installType != common.InstallTypeLinuxServer {
+		return nil, common.InstallFindError{
+			Path:  dir,
+			Inner: fmt.Errorf("not a linux dedicated server"),
+		}
+	}
+
+	branch, _ := common.BranchFromVersionFile(version.BranchName)
+	branchKey := version.BranchName
+	manifestPath := filepath.Join(dir, "steamapps", steam.DedicatedServerManifest)
+	common.AddManifestDir(filepath.Dir(manifestPath), "steamcmd", common.ManifestNames(steam.DedicatedServerManifest))
+	if _, err := os.Stat(manifestPath); err == nil {
+		appManifest, err := steam.ReadAppManifest(manifestPath)
+		if err != nil {
+			return nil, common.InstallFindError{
+				Path:  dir,
+				Inner: err,
+			}
+		}
+		branch, _ = common.BranchFromSteamBetaKey(appManifest.BetaKey)
+		branchKey = appManifest.BetaKey
+	}
+
+	return &common.Installation{
+		Path:        filepath.Clean(dir),
+		Version:     version.Changelist,
+		GameVersion: &version,
+		Type:        installType,
+		Location:    common.LocationTypeLocal,
+		Branch:      branch,
+		BranchKey:   branchKey,
+		Launcher:    launcher,
+		LaunchPath:  []string{filepath.Join(dir, "FactoryServer.sh")},
+	}, nil
+}
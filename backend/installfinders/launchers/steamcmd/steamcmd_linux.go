@@ -0,0 +1,77 @@
+package steamcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/steam"
+)
+
+func init() {
+	launchers.Add("SteamCMD", func() ([]*common.Installation, []error) {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, []error{fmt.Errorf("failed to get user home dir: %w", err)}
+		}
+
+		installs := make([]*common.Installation, 0)
+		var findErrors []error
+
+		// Without force_install_dir, SteamCMD installs to its own library in ~/Steam
+		steamPath := filepath.Join(homeDir, "Steam")
+		if _, err := os.Stat(filepath.Join(steamPath, "steamapps", "libraryfolders.vdf")); err == nil {
+			libraryInstalls, libraryErrors := steam.FindInstallationsSteam(steamPath, "SteamCMD", nil, nil)
+			for _, install := range libraryInstalls {
+				if install.Type != common.InstallTypeLinuxServer {
+					continue
+				}
+				install.LaunchPath = []string{filepath.Join(install.Path, "FactoryServer.sh")}
+				install.LaunchType = common.LaunchTypeDirect
+				installs = append(installs, install)
+			}
+			findErrors = append(findErrors, libraryErrors...)
+		}
+
+		// With force_install_dir, the server is usually in a directory of its own
+		for _, searchDir := range []string{homeDir, "/opt", "/srv"} {
+			dirInstalls, dirErrors := findForceInstallDirs(searchDir)
+			installs = append(installs, dirInstalls...)
+			findErrors = append(findErrors, dirErrors...)
+		}
+
+		if len(installs) == 0 && len(findErrors) == 0 {
+			return nil, []error{fmt.Errorf("no steamcmd servers found")}
+		}
+		return installs, findErrors
+	})
+}
+
+// findForceInstallDirs finds the servers in the subdirectories of searchDir that SteamCMD installed with force_install_dir
+func findForceInstallDirs(searchDir string) ([]*common.Installation, []error) {
+	entries, err := os.ReadDir(searchDir)
+	if err != nil {
+		return nil, nil
+	}
+
+	installs := make([]*common.Installation, 0)
+	var findErrors []error
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(searchDir, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, "steamapps", steam.DedicatedServerManifest)); err != nil {
+			continue
+		}
+		install, err := ServerInstallation(dir, "SteamCMD")
+		if err != nil {
+			findErrors = append(findErrors, err)
+			continue
+		}
+		installs = append(installs, install)
+	}
+	return installs, findErrors
+}
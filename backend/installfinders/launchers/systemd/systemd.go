@@ -0,0 +1,218 @@
+package systemd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+const serverScript = "FactoryServer.sh"
+
+// Unit is the part of a systemd service unit used to find the server it runs
+type Unit struct {
+	Name             string
+	WorkingDirectory string
+	ExecStart        []string
+}
+
+// FindInstallationsIn finds the servers run by the service units in unitDirs.
+// Earlier directories take precedence, like in systemd, so a unit overridden in /etc is only read from there.
+// The drop-ins of each unit are applied from all the directories.
+func FindInstallationsIn(unitDirs []string, user bool) ([]*common.Installation, []error) {
+	seen := make(map[string]bool)
+	installs := make([]*common.Installation, 0)
+	var findErrors []error
+
+	for _, unitDir := range unitDirs {
+		entries, err := os.ReadDir(unitDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".service") || seen[entry.Name()] {
+				continue
+			}
+			seen[entry.Name()] = true
+
+			unit, err := ParseUnit(filepath.Join(unitDir, entry.Name()), findDropIns(unitDirs, entry.Name())...)
+			if err != nil {
+				findErrors = append(findErrors, err)
+				continue
+			}
+
+			serverDir, ok := unit.ServerDir()
+			if !ok {
+				continue
+			}
+
+			installType, version, err := common.GetGameInfo(serverDir)
+			if err != nil {
+				findErrors = append(findErrors, common.InstallFindError{
+					Path:  serverDir,
+					Inner: fmt.Errorf("referenced by %s: %w", unit.Name, err),
+				})
+				continue
+			}
+
+			branch, _ := common.BranchFromVersionFile(version.BranchName)
+			installs = append(installs, &common.Installation{
+				Path:        filepath.Clean(serverDir),
+				Version:     version.Changelist,
+				GameVersion: &version,
+				Type:        installType,
+				Location:    common.LocationTypeLocal,
+				Branch:      branch,
+				BranchKey:   version.BranchName,
+				Launcher:    "systemd - " + strings.TrimSuffix(unit.Name, ".service"),
+				LaunchPath:  systemctl(user, "start", unit.Name),
+				LaunchType:  common.LaunchTypeHandoff,
+				StopPath:    systemctl(user, "stop", unit.Name),
+				SystemdUnit: unit.Name,
+			})
+		}
+	}
+	return installs, findErrors
+}
+
+func systemctl(user bool, args ...string) []string {
+	cmd := []string{"systemctl"}
+	if user {
+		cmd = append(cmd, "--user")
+	}
+	return append(cmd, args...)
+}
+
+// findDropIns returns the .conf files in the <unit>.d directories of unitDirs, in the order systemd applies them.
+// A drop-in in an earlier directory replaces the one with the same name in later directories.
+func findDropIns(unitDirs []string, unitName string) []string {
+	dropIns := make(map[string]string)
+	for _, unitDir := range unitDirs {
+		entries, err := os.ReadDir(filepath.Join(unitDir, unitName+".d"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".conf") {
+				continue
+			}
+			if _, ok := dropIns[entry.Name()]; ok {
+				continue
+			}
+			dropIns[entry.Name()] = filepath.Join(unitDir, unitName+".d", entry.Name())
+		}
+	}
+
+	names := make([]string, 0, len(dropIns))
+	for name := range dropIns {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, dropIns[name])
+	}
+	return paths
+}
+
+// ParseUnit reads the [Service] section of a unit file, then of its drop-ins, which override it.
+// Only the last ExecStart is kept, since services running the server have a single one.
+func ParseUnit(unitPath string, dropIns ...string) (*Unit, error) {
+	unit := &Unit{Name: filepath.Base(unitPath)}
+	for _, path := range append([]string{unitPath}, dropIns...) {
+		if err := unit.parse(path); err != nil {
+			return nil, err
+		}
+	}
+	return unit, nil
+}
+
+func (u *Unit) parse(unitPath string) error {
+	f, err := os.Open(unitPath)
+	if err != nil {
+		return fmt.Errorf("failed to open unit file %s: %w", unitPath, err)
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line
+			continue
+		}
+		if section != "[Service]" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "WorkingDirectory":
+			// A leading - means systemd ignores the directory if it is missing
+			u.WorkingDirectory = strings.TrimPrefix(strings.TrimSpace(value), "-")
+		case "ExecStart":
+			// An empty ExecStart, used by drop-ins before setting their own, clears it
+			u.ExecStart = splitCommand(strings.TrimSpace(value))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read unit file %s: %w", unitPath, err)
+	}
+	return nil
+}
+
+// ServerDir returns the directory of the FactoryServer.sh the unit runs
+func (u *Unit) ServerDir() (string, bool) {
+	for _, arg := range u.ExecStart {
+		if filepath.Base(arg) != serverScript {
+			continue
+		}
+		if filepath.IsAbs(arg) {
+			return filepath.Dir(arg), true
+		}
+		if u.WorkingDirectory == "" || !filepath.IsAbs(u.WorkingDirectory) {
+			return "", false
+		}
+		return filepath.Join(u.WorkingDirectory, filepath.Dir(arg)), true
+	}
+	return "", false
+}
+
+// splitCommand splits an ExecStart value into its arguments, removing the systemd prefixes of the executable.
+// Quoted arguments are kept together, but escapes are not handled.
+func splitCommand(value string) []string {
+	value = strings.TrimLeft(value, "-@:+!")
+
+	var args []string
+	var current strings.Builder
+	var quote rune
+	for _, c := range value {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && (c == ' ' || c == '\t'):
+			if current.Len() > 0 {
+				args = append(args, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(c)
+		}
+	}
+	if current.Len() > 0 {
+		args = append(args, current.String())
+	}
+	return args
+}
@@ -0,0 +1,45 @@
+package systemd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers"
+)
+
+// In the order systemd reads them, see systemd.unit(5)
+var (
+	systemUnitDirs = []string{
+		"/etc/systemd/system",
+		"/run/systemd/system",
+		"/usr/local/lib/systemd/system",
+		"/usr/lib/systemd/system",
+		"/lib/systemd/system",
+	}
+	globalUserUnitDirs = []string{
+		"/etc/systemd/user",
+		"/usr/local/lib/systemd/user",
+		"/usr/lib/systemd/user",
+	}
+)
+
+func init() {
+	launchers.Add("systemd", func() ([]*common.Installation, []error) {
+		return FindInstallationsIn(systemUnitDirs, false)
+	})
+	launchers.Add("systemd-user", func() ([]*common.Installation, []error) {
+		unitDirs := make([]string, 0, len(globalUserUnitDirs)+1)
+		configDir := os.Getenv("XDG_CONFIG_HOME")
+		if configDir == "" {
+			if homeDir, err := os.UserHomeDir(); err == nil {
+				configDir = filepath.Join(homeDir, ".config")
+			}
+		}
+		if configDir != "" {
+			unitDirs = append(unitDirs, filepath.Join(configDir, "systemd", "user"))
+		}
+		unitDirs = append(unitDirs, globalUserUnitDirs...)
+		return FindInstallationsIn(unitDirs, true)
+	})
+}
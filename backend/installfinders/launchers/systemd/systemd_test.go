@@ -0,0 +1,146 @@
+//go:build !windows
+
+package systemd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+var testUnitDirs = []string{filepath.Join("testdata", "etc"), filepath.Join("testdata", "lib")}
+
+func TestParseUnit(t *testing.T) {
+	unit, err := ParseUnit(filepath.Join("testdata", "etc", "satisfactory.service"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unit.Name != "satisfactory.service" {
+		t.Errorf("Name = %q, expected satisfactory.service", unit.Name)
+	}
+	if unit.WorkingDirectory != "/srv/satisfactory" {
+		t.Errorf("WorkingDirectory = %q, expected /srv/satisfactory", unit.WorkingDirectory)
+	}
+	expected := []string{"/srv/satisfactory/FactoryServer.sh", "-multihome=0.0.0.0", "-log"}
+	if !slices.Equal(unit.ExecStart, expected) {
+		t.Errorf("ExecStart = %q, expected %q", unit.ExecStart, expected)
+	}
+}
+
+func TestParseUnitDropIns(t *testing.T) {
+	dropIns := findDropIns(testUnitDirs, "satisfactory.service")
+	expectedDropIns := []string{
+		filepath.Join("testdata", "lib", "satisfactory.service.d", "10-workdir.conf"),
+		filepath.Join("testdata", "etc", "satisfactory.service.d", "20-port.conf"),
+	}
+	if !slices.Equal(dropIns, expectedDropIns) {
+		t.Fatalf("findDropIns = %q, expected %q", dropIns, expectedDropIns)
+	}
+
+	unit, err := ParseUnit(filepath.Join("testdata", "etc", "satisfactory.service"), dropIns...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unit.WorkingDirectory != "/srv/satisfactory-server" {
+		t.Errorf("WorkingDirectory = %q, expected /srv/satisfactory-server", unit.WorkingDirectory)
+	}
+	expected := []string{"/srv/satisfactory/FactoryServer.sh", "-Port=7778"}
+	if !slices.Equal(unit.ExecStart, expected) {
+		t.Errorf("ExecStart = %q, expected %q", unit.ExecStart, expected)
+	}
+}
+
+func TestServerDir(t *testing.T) {
+	tests := []struct {
+		unit Unit
+		dir  string
+		ok   bool
+	}{
+		{Unit{ExecStart: []string{"/srv/satisfactory/FactoryServer.sh", "-log"}}, "/srv/satisfactory", true},
+		{Unit{WorkingDirectory: "/srv", ExecStart: []string{"/bin/sh", "satisfactory/FactoryServer.sh"}}, "/srv/satisfactory", true},
+		{Unit{ExecStart: []string{"/bin/sh", "./FactoryServer.sh"}}, "", false},
+		{Unit{ExecStart: []string{"/usr/bin/sleep", "infinity"}}, "", false},
+	}
+	for _, test := range tests {
+		dir, ok := test.unit.ServerDir()
+		if dir != test.dir || ok != test.ok {
+			t.Errorf("ServerDir() of %q = %q, %t, expected %q, %t", test.unit.ExecStart, dir, ok, test.dir, test.ok)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		value string
+		args  []string
+	}{
+		{"", nil},
+		{"/srv/FactoryServer.sh", []string{"/srv/FactoryServer.sh"}},
+		{"-/srv/FactoryServer.sh  -log\t-Port=7777", []string{"/srv/FactoryServer.sh", "-log", "-Port=7777"}},
+		{"@/srv/FactoryServer.sh FactoryServer", []string{"/srv/FactoryServer.sh", "FactoryServer"}},
+		{`"/srv/Satisfactory Server/FactoryServer.sh" '-ini:Engine:[Core.Log]:LogNet=Verbose'`, []string{"/srv/Satisfactory Server/FactoryServer.sh", "-ini:Engine:[Core.Log]:LogNet=Verbose"}},
+		{`/bin/sh -c "cd /srv && ./FactoryServer.sh"`, []string{"/bin/sh", "-c", "cd /srv && ./FactoryServer.sh"}},
+	}
+	for _, test := range tests {
+		if args := splitCommand(test.value); !slices.Equal(args, test.args) {
+			t.Errorf("splitCommand(%q) = %q, expected %q", test.value, args, test.args)
+		}
+	}
+}
+
+func TestFindInstallationsIn(t *testing.T) {
+	serverDir, err := filepath.Abs(filepath.Join("testdata", "server"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server path must be absolute, so the units referencing the server are written here
+	unitDir := t.TempDir()
+	writeFile(t, filepath.Join(unitDir, "satisfactory.service"), "[Service]\nExecStart=/opt/satisfactory/FactoryServer.sh\n")
+	writeFile(t, filepath.Join(unitDir, "satisfactory.service.d", "20-port.conf"), "[Service]\nExecStart=\nExecStart="+filepath.Join(serverDir, "FactoryServer.sh")+" -Port=7778\n")
+	writeFile(t, filepath.Join(unitDir, "missing.service"), "[Service]\nExecStart=/nonexistent/FactoryServer.sh\n")
+
+	installs, findErrors := FindInstallationsIn([]string{unitDir, filepath.Join("testdata", "lib")}, true)
+
+	if len(installs) != 1 {
+		t.Fatalf("found %d installations, expected 1", len(installs))
+	}
+	install := installs[0]
+	if install.Path != serverDir {
+		t.Errorf("Path = %q, expected %q", install.Path, serverDir)
+	}
+	if install.Type != common.InstallTypeLinuxServer || install.Version != 365306 {
+		t.Errorf("Type, Version = %s, %d, expected %s, 365306", install.Type, install.Version, common.InstallTypeLinuxServer)
+	}
+	if install.Launcher != "systemd - satisfactory" || install.SystemdUnit != "satisfactory.service" {
+		t.Errorf("Launcher, SystemdUnit = %q, %q", install.Launcher, install.SystemdUnit)
+	}
+	if expected := []string{"systemctl", "--user", "start", "satisfactory.service"}; !slices.Equal(install.LaunchPath, expected) {
+		t.Errorf("LaunchPath = %q, expected %q", install.LaunchPath, expected)
+	}
+	if expected := []string{"systemctl", "--user", "stop", "satisfactory.service"}; !slices.Equal(install.StopPath, expected) {
+		t.Errorf("StopPath = %q, expected %q", install.StopPath, expected)
+	}
+
+	if len(findErrors) != 1 {
+		t.Fatalf("got %d errors, expected 1: %v", len(findErrors), findErrors)
+	}
+	var installFindErr common.InstallFindError
+	if !errors.As(findErrors[0], &installFindErr) || installFindErr.Path != "/nonexistent" {
+		t.Errorf("expected an InstallFindError for /nonexistent, got %v", findErrors[0])
+	}
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,65 @@
+package installfinders
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+const ManualLauncher = "Manual"
+
+// ManualInstallation checks that path is a game installation, and describes it as one added by the user instead of found by a launcher.
+// If launchCommand is empty, the game executable is launched directly.
+func ManualInstallation(path string, launchCommand []string) (*common.Installation, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	installType, version, err := common.GetGameInfo(path)
+	if err != nil {
+		return nil, common.InstallFindError{
+			Path:  path,
+			Inner: err,
+		}
+	}
+
+	if len(launchCommand) == 0 {
+		launchCommand = defaultLaunchCommand(path, installType)
+		if len(launchCommand) == 0 {
+			return nil, fmt.Errorf("a launch command is required for this installation")
+		}
+	}
+
+	// There is no launcher manifest, so the branch can only be read from the version file
+	branch, _ := common.BranchFromVersionFile(version.BranchName)
+
+	return &common.Installation{
+		Path:        path,
+		Version:     version.Changelist,
+		GameVersion: &version,
+		Type:        installType,
+		Location:    common.LocationTypeLocal,
+		Branch:      branch,
+		BranchKey:   version.BranchName,
+		Launcher:    ManualLauncher,
+		LaunchPath:  launchCommand,
+	}, nil
+}
+
+func defaultLaunchCommand(path string, installType common.InstallType) []string {
+	switch installType {
+	case common.InstallTypeWindowsServer:
+		return []string{filepath.Join(path, "FactoryServer.exe")}
+	case common.InstallTypeLinuxServer:
+		return []string{filepath.Join(path, "FactoryServer.sh")}
+	default:
+		if runtime.GOOS != "windows" {
+			// The client can only run through wine, which the user has to set up in the launch command
+			return nil
+		}
+		return []string{filepath.Join(path, "FactoryGame.exe")}
+	}
+}
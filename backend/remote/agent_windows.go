@@ -0,0 +1,39 @@
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// openSSHAgentPipe is the named pipe the Windows OpenSSH agent listens on
+const openSSHAgentPipe = `\\.\pipe\openssh-ssh-agent`
+
+// dialAgent connects to the ssh agent named by SSH_AUTH_SOCK, or to the Windows OpenSSH agent if it is not set.
+// Pageant can be used by setting SSH_AUTH_SOCK to the named pipe it listens on, as printed by pageant --openssh-config.
+func dialAgent() (io.ReadWriteCloser, error) {
+	path := os.Getenv("SSH_AUTH_SOCK")
+	if path == "" {
+		path = openSSHAgentPipe
+	}
+
+	if !strings.HasPrefix(path, `\\.\pipe\`) {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ssh agent: %w", err)
+		}
+		return conn, nil
+	}
+
+	// Named pipes can be used as regular files, which is all the agent protocol needs
+	pipe, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("ssh agent is not running (nothing is listening on %s)", path)
+		}
+		return nil, fmt.Errorf("failed to connect to ssh agent: %w", err)
+	}
+	return pipe, nil
+}
@@ -0,0 +1,90 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
+)
+
+var ErrPassphraseRequired = errors.New("private key is protected by a passphrase")
+
+// Auth holds the SSH authentication methods configured by a credential.
+// It must be closed once the handshake is done.
+type Auth struct {
+	Methods []ssh.AuthMethod
+
+	agentConn io.ReadWriteCloser
+}
+
+// NewAuth returns the SSH authentication methods configured by the credential.
+// They are tried in order: agent, private key, then password.
+func NewAuth(credential credentials.Credential) (*Auth, error) {
+	a := &Auth{}
+
+	if credential.UseAgent {
+		conn, err := dialAgent()
+		if err != nil {
+			return nil, err
+		}
+		a.agentConn = conn
+		a.Methods = append(a.Methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+
+	if credential.PrivateKeyPath != "" {
+		signer, err := loadPrivateKey(credential.PrivateKeyPath, credential.Passphrase)
+		if err != nil {
+			_ = a.Close()
+			return nil, err
+		}
+		a.Methods = append(a.Methods, ssh.PublicKeys(signer))
+	}
+
+	if credential.Password != "" {
+		a.Methods = append(a.Methods, ssh.Password(credential.Password))
+	}
+
+	return a, nil
+}
+
+func (a *Auth) Close() error {
+	if a.agentConn == nil {
+		return nil
+	}
+	err := a.agentConn.Close()
+	a.agentConn = nil
+	if err != nil {
+		return fmt.Errorf("failed to close ssh agent connection: %w", err)
+	}
+	return nil
+}
+
+func loadPrivateKey(path string, passphrase string) (ssh.Signer, error) {
+	keyBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+
+	if passphrase != "" {
+		signer, err := ssh.ParsePrivateKeyWithPassphrase(keyBytes, []byte(passphrase))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key: %w", err)
+		}
+		return signer, nil
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyBytes)
+	if err != nil {
+		var missingErr *ssh.PassphraseMissingError
+		if errors.As(err, &missingErr) {
+			return nil, ErrPassphraseRequired
+		}
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return signer, nil
+}
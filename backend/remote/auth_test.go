@@ -0,0 +1,139 @@
+package remote
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
+)
+
+func writePrivateKey(t *testing.T, passphrase string) string {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(key, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte(passphrase))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewAuthPassword(t *testing.T) {
+	auth, err := NewAuth(credentials.Credential{Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auth.Close()
+	if len(auth.Methods) != 1 {
+		t.Fatalf("expected 1 method, got %d", len(auth.Methods))
+	}
+}
+
+func TestNewAuthPrivateKey(t *testing.T) {
+	auth, err := NewAuth(credentials.Credential{PrivateKeyPath: writePrivateKey(t, ""), Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auth.Close()
+	if len(auth.Methods) != 2 {
+		t.Fatalf("expected 2 methods, got %d", len(auth.Methods))
+	}
+}
+
+func TestNewAuthEncryptedPrivateKey(t *testing.T) {
+	path := writePrivateKey(t, "secret")
+
+	_, err := NewAuth(credentials.Credential{PrivateKeyPath: path})
+	if !errors.Is(err, ErrPassphraseRequired) {
+		t.Fatalf("expected ErrPassphraseRequired, got %v", err)
+	}
+
+	_, err = NewAuth(credentials.Credential{PrivateKeyPath: path, Passphrase: "wrong"})
+	if err == nil {
+		t.Fatal("expected an error for a wrong passphrase")
+	}
+
+	auth, err := NewAuth(credentials.Credential{PrivateKeyPath: path, Passphrase: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auth.Close()
+	if len(auth.Methods) != 1 {
+		t.Fatalf("expected 1 method, got %d", len(auth.Methods))
+	}
+}
+
+func TestNewAuthMissingPrivateKey(t *testing.T) {
+	_, err := NewAuth(credentials.Credential{PrivateKeyPath: filepath.Join(t.TempDir(), "missing")})
+	if err == nil {
+		t.Fatal("expected an error for a missing key")
+	}
+}
+
+func TestNewAuthAgent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the agent is a named pipe on windows")
+	}
+
+	t.Setenv("SSH_AUTH_SOCK", "")
+	_, err := NewAuth(credentials.Credential{UseAgent: true})
+	if err == nil {
+		t.Fatal("expected an error without an agent")
+	}
+
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	keyring := agent.NewKeyring()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+
+	t.Setenv("SSH_AUTH_SOCK", socket)
+	auth, err := NewAuth(credentials.Credential{UseAgent: true, Password: "pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(auth.Methods) != 2 {
+		t.Fatalf("expected 2 methods, got %d", len(auth.Methods))
+	}
+	if err := auth.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.Close(); err != nil {
+		t.Fatalf("closing twice should not fail: %v", err)
+	}
+}
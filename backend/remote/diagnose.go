@@ -0,0 +1,361 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/pkg/sftp"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+)
+
+type DiagnosticStageName string
+
+var (
+	StageParse       DiagnosticStageName = "parse"
+	StageDNS         DiagnosticStageName = "dns"
+	StageConnect     DiagnosticStageName = "connect"
+	StageHandshake   DiagnosticStageName = "handshake"
+	StageAuth        DiagnosticStageName = "auth"
+	StageList        DiagnosticStageName = "list"
+	StageWrite       DiagnosticStageName = "write"
+	StageVersionFile DiagnosticStageName = "versionFile"
+)
+
+var AllDiagnosticStages = []struct {
+	Value  DiagnosticStageName
+	TSName string
+}{
+	{StageParse, "PARSE"},
+	{StageDNS, "DNS"},
+	{StageConnect, "CONNECT"},
+	{StageHandshake, "HANDSHAKE"},
+	{StageAuth, "AUTH"},
+	{StageList, "LIST"},
+	{StageWrite, "WRITE"},
+	{StageVersionFile, "VERSION_FILE"},
+}
+
+type DiagnosticStageStatus string
+
+var (
+	StageStatusOK      DiagnosticStageStatus = "ok"
+	StageStatusFailed  DiagnosticStageStatus = "failed"
+	StageStatusSkipped DiagnosticStageStatus = "skipped"
+)
+
+var AllDiagnosticStageStatuses = []struct {
+	Value  DiagnosticStageStatus
+	TSName string
+}{
+	{StageStatusOK, "OK"},
+	{StageStatusFailed, "FAILED"},
+	{StageStatusSkipped, "SKIPPED"},
+}
+
+type DiagnosticStage struct {
+	Name      DiagnosticStageName   `json:"name"`
+	Status    DiagnosticStageStatus `json:"status"`
+	LatencyMs int64                 `json:"latencyMs"`
+	Detail    string                `json:"detail,omitempty"`
+	Error     string                `json:"error,omitempty"`
+	// Set when the handshake failed because the host key is not trusted yet
+	UnknownHostKey *UnknownHostKeyError `json:"unknownHostKey,omitempty"`
+}
+
+type Diagnosis struct {
+	Stages      []DiagnosticStage   `json:"stages"`
+	FailedStage DiagnosticStageName `json:"failedStage,omitempty"`
+	Success     bool                `json:"success"`
+}
+
+const (
+	diagnoseTimeout = 10 * time.Second
+	writeTestFile   = ".smm-write-test"
+)
+
+// run runs a stage, unless an earlier one failed, in which case it is reported as skipped
+func (d *Diagnosis) run(name DiagnosticStageName, stage func() (string, error)) {
+	if d.FailedStage != "" {
+		d.skip(name)
+		return
+	}
+	start := time.Now()
+	detail, err := stage()
+	d.record(name, time.Since(start), detail, err)
+}
+
+func (d *Diagnosis) skip(name DiagnosticStageName) {
+	d.Stages = append(d.Stages, DiagnosticStage{Name: name, Status: StageStatusSkipped})
+}
+
+func (d *Diagnosis) record(name DiagnosticStageName, latency time.Duration, detail string, err error) {
+	result := DiagnosticStage{
+		Name:      name,
+		Status:    StageStatusOK,
+		LatencyMs: latency.Milliseconds(),
+		Detail:    detail,
+	}
+	if err != nil {
+		result.Status = StageStatusFailed
+		result.Error = err.Error()
+		var unknownHostKeyErr *UnknownHostKeyError
+		if errors.As(err, &unknownHostKeyErr) {
+			result.UnknownHostKey = unknownHostKeyErr
+		}
+		d.FailedStage = name
+	}
+	d.Stages = append(d.Stages, result)
+}
+
+// Diagnose connects to the server at rawURL one stage at a time, so that the exact stage that fails can be reported.
+// Stages after the first failure are reported as skipped.
+func Diagnose(rawURL string, credential credentials.Credential, hostKeyCallback ssh.HostKeyCallback) Diagnosis {
+	var result Diagnosis
+
+	var u *url.URL
+	var port string
+	result.run(StageParse, func() (string, error) {
+		var err error
+		u, err = url.Parse(rawURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse path: %w", err)
+		}
+		switch u.Scheme {
+		case "ftp":
+			port = "21"
+		case "sftp":
+			port = defaultSFTPPort
+		default:
+			return "", fmt.Errorf("unsupported scheme %q, expected ftp or sftp", u.Scheme)
+		}
+		if u.Hostname() == "" {
+			return "", fmt.Errorf("missing host")
+		}
+		if u.Port() != "" {
+			port = u.Port()
+		}
+		return u.Scheme, nil
+	})
+
+	result.run(StageDNS, func() (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), diagnoseTimeout)
+		defer cancel()
+		addresses, err := net.DefaultResolver.LookupHost(ctx, u.Hostname())
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", u.Hostname(), err)
+		}
+		return strings.Join(addresses, ", "), nil
+	})
+
+	var conn net.Conn
+	result.run(StageConnect, func() (string, error) {
+		var err error
+		conn, err = net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), diagnoseTimeout)
+		if err != nil {
+			return "", fmt.Errorf("failed to connect to port %s: %w", port, err)
+		}
+		return conn.RemoteAddr().String(), nil
+	})
+
+	var d disk.Disk
+	if result.FailedStage == "" && u.Scheme == "ftp" {
+		d = diagnoseFTP(&result, u, conn, credential)
+	} else {
+		d = diagnoseSFTP(&result, u, conn, credential, hostKeyCallback)
+	}
+	if d != nil {
+		defer func() {
+			if closer, ok := d.(io.Closer); ok {
+				_ = closer.Close()
+			}
+		}()
+	} else if conn != nil {
+		_ = conn.Close()
+	}
+
+	basePath := "/"
+	if u != nil && u.Path != "" {
+		basePath = u.Path
+	}
+
+	result.run(StageList, func() (string, error) {
+		entries, err := d.ReadDir(basePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to list %s: %w", basePath, err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && (entry.Name() == "FactoryServer.sh" || entry.Name() == "FactoryServer.exe") {
+				return fmt.Sprintf("%d entries, found %s", len(entries), entry.Name()), nil
+			}
+		}
+		return "", fmt.Errorf("%s does not contain FactoryServer.sh or FactoryServer.exe, it is not a server directory", basePath)
+	})
+
+	result.run(StageWrite, func() (string, error) {
+		// Mods is created by the first install, so test the directory it would be created in if it doesn't exist yet
+		dir := path.Join(basePath, "FactoryGame", "Mods")
+		exists, err := d.Exists(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to check if %s exists: %w", dir, err)
+		}
+		if !exists {
+			dir = path.Dir(dir)
+		}
+		testPath := path.Join(dir, writeTestFile)
+		if err := d.Write(testPath, []byte("SMM write test")); err != nil {
+			return "", fmt.Errorf("no write permission in %s: %w", dir, err)
+		}
+		if err := d.Remove(testPath); err != nil {
+			return "", fmt.Errorf("failed to remove %s: %w", testPath, err)
+		}
+		return "writable: " + dir, nil
+	})
+
+	result.run(StageVersionFile, func() (string, error) {
+		for _, versionPath := range common.ServerVersionFilePaths() {
+			fullPath := path.Join(basePath, versionPath)
+			exists, err := d.Exists(fullPath)
+			if err != nil {
+				return "", fmt.Errorf("failed to check if %s exists: %w", fullPath, err)
+			}
+			if !exists {
+				continue
+			}
+			data, err := d.Read(fullPath)
+			if err != nil {
+				return "", fmt.Errorf("failed to read %s: %w", fullPath, err)
+			}
+			var versionData common.GameVersionFile
+			if err := json.Unmarshal(data, &versionData); err != nil {
+				return "", fmt.Errorf("failed to parse %s: %w", fullPath, err)
+			}
+			gameVersion := versionData.GameVersion()
+			return fmt.Sprintf("%s, version %s, changelist %d, branch %s, build %s", versionPath, gameVersion.Version, gameVersion.Changelist, gameVersion.BranchName, gameVersion.BuildID), nil
+		}
+		return "", fmt.Errorf("no game version file found")
+	})
+
+	result.Success = result.FailedStage == ""
+	return result
+}
+
+func diagnoseFTP(result *Diagnosis, u *url.URL, conn net.Conn, credential credentials.Credential) disk.Disk {
+	// Saved servers keep their password in the credential store rather than in the URL
+	if credential.Password != "" {
+		u.User = url.UserPassword(u.User.Username(), credential.Password)
+	}
+
+	var c *ftp.ServerConn
+	result.run(StageHandshake, func() (string, error) {
+		var err error
+		c, err = ftp.Dial(conn.RemoteAddr().String(), ftp.DialWithNetConn(conn), ftp.DialWithTimeout(diagnoseTimeout))
+		if err != nil {
+			return "", fmt.Errorf("ftp handshake failed: %w", err)
+		}
+		return "", nil
+	})
+
+	var d *FTPDisk
+	result.run(StageAuth, func() (string, error) {
+		password, _ := u.User.Password()
+		if err := c.Login(u.User.Username(), password); err != nil {
+			_ = c.Quit()
+			return "", fmt.Errorf("failed to login: %w", err)
+		}
+		// The remaining stages use the same disk as SMM, which makes its own connections
+		_ = c.Quit()
+		var err error
+		d, err = NewFTP(u)
+		if err != nil {
+			return "", err
+		}
+		return "logged in as " + u.User.Username(), nil
+	})
+
+	if d == nil {
+		return nil
+	}
+	return d
+}
+
+func diagnoseSFTP(result *Diagnosis, u *url.URL, conn net.Conn, credential credentials.Credential, hostKeyCallback ssh.HostKeyCallback) disk.Disk {
+	if result.FailedStage != "" {
+		result.skip(StageHandshake)
+		result.skip(StageAuth)
+		return nil
+	}
+
+	// The handshake does not need any credentials, so it is still checked if they can't be loaded
+	var methods []ssh.AuthMethod
+	auth, authLoadErr := NewAuth(credential)
+	if authLoadErr == nil {
+		methods = auth.Methods
+		defer auth.Close()
+	}
+
+	// SSH authenticates as part of the handshake,
+	// so the host key check is used to tell which of the two failed
+	var hostKey string
+	var handshakeDone time.Time
+	config := &ssh.ClientConfig{
+		User: u.User.Username(),
+		Auth: methods,
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hostKey = key.Type() + " " + ssh.FingerprintSHA256(key)
+			if err := hostKeyCallback(hostname, remote, key); err != nil {
+				return err
+			}
+			handshakeDone = time.Now()
+			return nil
+		},
+		Timeout: diagnoseTimeout,
+	}
+
+	start := time.Now()
+	_ = conn.SetDeadline(start.Add(diagnoseTimeout))
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, conn.RemoteAddr().String(), config)
+	if handshakeDone.IsZero() {
+		result.record(StageHandshake, time.Since(start), hostKey, fmt.Errorf("ssh handshake failed: %w", err))
+		result.skip(StageAuth)
+		return nil
+	}
+	result.record(StageHandshake, handshakeDone.Sub(start), hostKey, nil)
+
+	if err == nil && authLoadErr != nil {
+		// The server accepted the connection without any credentials, but the configured ones are still broken
+		_ = sshConn.Close()
+		err = authLoadErr
+	}
+	if err != nil {
+		result.record(StageAuth, time.Since(handshakeDone), "", fmt.Errorf("failed to authenticate: %w", err))
+		return nil
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		result.record(StageAuth, time.Since(handshakeDone), "", fmt.Errorf("failed to start sftp: %w", err))
+		return nil
+	}
+	result.record(StageAuth, time.Since(handshakeDone), "logged in as "+u.User.Username(), nil)
+
+	return &SFTPDisk{
+		conn:   sshClient,
+		client: client,
+	}
+}
@@ -0,0 +1,27 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
+)
+
+func TestDiagnoseFTPWithCredential(t *testing.T) {
+	server := newFakeFTPServer(t, "user", "pass")
+	server.addFile("/server/FactoryServer.sh", []byte("#!/bin/sh"))
+	server.addFile("/server/Engine/Binaries/Linux/FactoryServer-Linux-Shipping.version", []byte(`{"MajorVersion":5,"Changelist":365306,"BranchName":"++FactoryGame+rel-main-1.0.0"}`))
+
+	// Saved servers don't have the password in the URL, only in the credential
+	u := server.url(false)
+	u.Path = "/server"
+
+	result := Diagnose(u.String(), credentials.Credential{Password: "pass"}, nil)
+	if !result.Success {
+		t.Fatalf("expected the diagnosis to succeed, failed at %q: %+v", result.FailedStage, result.Stages)
+	}
+	for _, stage := range result.Stages {
+		if stage.Name == StageAuth && stage.Status != StageStatusOK {
+			t.Errorf("expected the auth stage to succeed, got %q: %s", stage.Status, stage.Error)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+)
+
+var _ disk.Disk = (*FTPDisk)(nil)
+
+// ftpConnectionCount is how many connections an FTPDisk opens at most, since operations on a connection can't run in parallel
+const ftpConnectionCount = 5
+
+const ftpTimeout = 10 * time.Second
+
+// FTPDisk is a disk.Disk over FTP that, unlike the ficsit-cli implementation, can be closed, logging out of its connections.
+// Connections are only made when first needed, and are kept open for the next operations until the disk is closed.
+type FTPDisk struct {
+	u *url.URL
+	// slots limits the connections in use to ftpConnectionCount
+	slots chan struct{}
+
+	lock   sync.Mutex
+	idle   []*ftp.ServerConn
+	closed bool
+}
+
+type ftpEntry struct {
+	*ftp.Entry
+}
+
+func (e ftpEntry) IsDir() bool {
+	return e.Entry.Type == ftp.EntryTypeFolder
+}
+
+func (e ftpEntry) Name() string {
+	return e.Entry.Name
+}
+
+// NewFTP creates a disk for the ftp:// URL u, using the password in the URL.
+// Connections are only made when first needed.
+func NewFTP(u *url.URL) (*FTPDisk, error) {
+	if u.Scheme != "ftp" {
+		return nil, fmt.Errorf("not an ftp url: %s", u.Redacted())
+	}
+	return &FTPDisk{
+		u:     u,
+		slots: make(chan struct{}, ftpConnectionCount),
+	}, nil
+}
+
+// Close logs out of the connections that are not in use.
+// Connections in use are logged out of once the operation using them is done.
+func (d *FTPDisk) Close() error {
+	d.lock.Lock()
+	d.closed = true
+	idle := d.idle
+	d.idle = nil
+	d.lock.Unlock()
+
+	for _, c := range idle {
+		quitFTP(c)
+	}
+	return nil
+}
+
+func quitFTP(c *ftp.ServerConn) {
+	if err := c.Quit(); err != nil {
+		slog.Debug("failed to close ftp connection", slog.Any("error", err))
+	}
+}
+
+// dialFTP connects and logs in to the server. Hidden files are listed if the server supports it.
+func dialFTP(u *url.URL) (*ftp.ServerConn, error) {
+	c, err := loginFTP(u, ftp.DialWithTimeout(ftpTimeout), ftp.DialWithForceListHidden(true))
+	if err == nil {
+		if _, err = c.List("/"); err == nil {
+			slog.Info("logged into ftp", slog.Bool("hidden-files", true))
+			return c, nil
+		}
+		quitFTP(c)
+	}
+
+	c, err = loginFTP(u, ftp.DialWithTimeout(ftpTimeout))
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("logged into ftp", slog.Bool("hidden-files", false))
+	return c, nil
+}
+
+func loginFTP(u *url.URL, options ...ftp.DialOption) (*ftp.ServerConn, error) {
+	c, err := ftp.Dial(u.Host, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial host %s: %w", u.Host, err)
+	}
+	password, _ := u.User.Password()
+	if err := c.Login(u.User.Username(), password); err != nil {
+		quitFTP(c)
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+	return c, nil
+}
+
+// acquire returns a connection at the root directory, reusing an idle one if there is any
+func (d *FTPDisk) acquire() (*ftp.ServerConn, error) {
+	d.slots <- struct{}{}
+
+	d.lock.Lock()
+	if d.closed {
+		d.lock.Unlock()
+		<-d.slots
+		return nil, fmt.Errorf("ftp disk is closed")
+	}
+	var c *ftp.ServerConn
+	if len(d.idle) > 0 {
+		c = d.idle[len(d.idle)-1]
+		d.idle = d.idle[:len(d.idle)-1]
+	}
+	d.lock.Unlock()
+
+	if c == nil {
+		var err error
+		c, err = dialFTP(d.u)
+		if err != nil {
+			<-d.slots
+			return nil, err
+		}
+	}
+
+	if err := c.ChangeDir("/"); err != nil {
+		d.release(c, err)
+		return nil, fmt.Errorf("failed to change directory: %w", err)
+	}
+	return c, nil
+}
+
+// release makes the connection available to the next operation.
+// It is logged out of instead if the disk was closed, or the operation failed for a reason other than the server refusing it.
+func (d *FTPDisk) release(c *ftp.ServerConn, opErr error) {
+	defer func() { <-d.slots }()
+
+	var protoErr *textproto.Error
+	if opErr != nil && !errors.As(opErr, &protoErr) {
+		quitFTP(c)
+		return
+	}
+
+	d.lock.Lock()
+	if d.closed {
+		d.lock.Unlock()
+		quitFTP(c)
+		return
+	}
+	d.idle = append(d.idle, c)
+	d.lock.Unlock()
+}
+
+func (d *FTPDisk) Exists(filePath string) (bool, error) {
+	c, err := d.acquire()
+	if err != nil {
+		return false, err
+	}
+
+	slog.Debug("checking if file exists", slog.String("path", clean(filePath)), slog.String("schema", "ftp"))
+
+	// Not all servers can list or stat a path that is not in the current directory, so each directory is entered in turn
+	split := strings.Split(strings.TrimPrefix(clean(filePath), "/"), "/")
+	for _, dir := range split[:len(split)-1] {
+		found, err := ftpHasEntry(c, dir, true)
+		if err != nil || !found {
+			d.release(c, err)
+			return false, err
+		}
+		if err := c.ChangeDir(dir); err != nil {
+			d.release(c, err)
+			return false, fmt.Errorf("failed to enter directory: %w", err)
+		}
+	}
+
+	found, err := ftpHasEntry(c, split[len(split)-1], false)
+	d.release(c, err)
+	return found, err
+}
+
+// ftpHasEntry returns whether the current directory contains the entry
+func ftpHasEntry(c *ftp.ServerConn, name string, mustBeDir bool) (bool, error) {
+	entries, err := c.List("")
+	if err != nil {
+		return false, fmt.Errorf("failed listing directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.Name == name && (!mustBeDir || entry.Type == ftp.EntryTypeFolder) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (d *FTPDisk) Read(filePath string) ([]byte, error) {
+	c, err := d.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("reading file", slog.String("path", clean(filePath)), slog.String("schema", "ftp"))
+
+	data, err := func() ([]byte, error) {
+		f, err := c.Retr(clean(filePath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve path: %w", err)
+		}
+		defer f.Close()
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		return data, nil
+	}()
+	d.release(c, err)
+	return data, err
+}
+
+func (d *FTPDisk) Write(filePath string, data []byte) error {
+	c, err := d.acquire()
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("writing to file", slog.String("path", clean(filePath)), slog.String("schema", "ftp"))
+	err = c.Stor(clean(filePath), bytes.NewReader(data))
+	d.release(c, err)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func (d *FTPDisk) Remove(filePath string) error {
+	c, err := d.acquire()
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("deleting path", slog.String("path", clean(filePath)), slog.String("schema", "ftp"))
+	err = c.Delete(clean(filePath))
+	if err != nil {
+		err = c.RemoveDirRecur(clean(filePath))
+	}
+	d.release(c, err)
+	if err != nil {
+		return fmt.Errorf("failed to delete path: %w", err)
+	}
+	return nil
+}
+
+func (d *FTPDisk) MkDir(dirPath string) error {
+	c, err := d.acquire()
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		for _, dir := range strings.Split(strings.TrimPrefix(clean(dirPath), "/"), "/") {
+			found, err := ftpHasEntry(c, dir, true)
+			if err != nil {
+				return err
+			}
+			if !found {
+				slog.Debug("making directory", slog.String("dir", dir), slog.String("schema", "ftp"))
+				if err := c.MakeDir(dir); err != nil {
+					return fmt.Errorf("failed to make directory: %w", err)
+				}
+			}
+			if err := c.ChangeDir(dir); err != nil {
+				return fmt.Errorf("failed to enter directory: %w", err)
+			}
+		}
+		return nil
+	}()
+	d.release(c, err)
+	return err
+}
+
+func (d *FTPDisk) ReadDir(dirPath string) ([]disk.Entry, error) {
+	c, err := d.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("reading directory", slog.String("path", clean(dirPath)), slog.String("schema", "ftp"))
+	dir, err := c.List(clean(dirPath))
+	d.release(c, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in directory: %w", err)
+	}
+
+	entries := make([]disk.Entry, len(dir))
+	for i, entry := range dir {
+		entries[i] = ftpEntry{Entry: entry}
+	}
+	return entries, nil
+}
+
+// Open returns a writer to the file. The connection is used until the writer is closed.
+func (d *FTPDisk) Open(filePath string, _ int) (io.WriteCloser, error) {
+	c, err := d.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Debug("opening for writing", slog.String("path", clean(filePath)), slog.String("schema", "ftp"))
+
+	reader, writer := io.Pipe()
+	go func() {
+		err := c.Stor(clean(filePath), reader)
+		// Unblock the writer if the upload failed before reading everything
+		_ = reader.CloseWithError(err)
+		d.release(c, err)
+		if err != nil {
+			slog.Error("failed to store file", slog.String("path", clean(filePath)), slog.Any("error", err))
+			return
+		}
+		slog.Debug("write success", slog.String("path", clean(filePath)), slog.String("schema", "ftp"))
+	}()
+	return writer, nil
+}
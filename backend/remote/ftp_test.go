@@ -0,0 +1,333 @@
+package remote
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFTPServer is an in-memory FTP server stand-in, supporting the commands the FTP disk uses
+type fakeFTPServer struct {
+	t        *testing.T
+	listener net.Listener
+	user     string
+	password string
+
+	lock  sync.Mutex
+	files map[string][]byte
+	dirs  map[string]bool
+	// logins counts the successful logins, which is how many connections were made
+	logins int
+	// quits counts the connections that were logged out of
+	quits int
+}
+
+func newFakeFTPServer(t *testing.T, user, password string) *fakeFTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeFTPServer{
+		t:        t,
+		listener: listener,
+		user:     user,
+		password: password,
+		files:    make(map[string][]byte),
+		dirs:     map[string]bool{"/": true},
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeFTPServer) url(withPassword bool) *url.URL {
+	u := &url.URL{Scheme: "ftp", Host: s.listener.Addr().String(), Path: "/"}
+	if withPassword {
+		u.User = url.UserPassword(s.user, s.password)
+	} else {
+		u.User = url.User(s.user)
+	}
+	return u
+}
+
+func (s *fakeFTPServer) addFile(filePath string, data []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.files[filePath] = data
+	for dir := path.Dir(filePath); !s.dirs[dir]; dir = path.Dir(dir) {
+		s.dirs[dir] = true
+	}
+}
+
+func (s *fakeFTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(format string, args ...any) {
+		_, _ = fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	cwd := "/"
+	resolve := func(p string) string {
+		if !path.IsAbs(p) {
+			p = path.Join(cwd, p)
+		}
+		return path.Clean(p)
+	}
+	var dataListener net.Listener
+	openData := func() net.Conn {
+		if dataListener == nil {
+			reply("425 no data connection")
+			return nil
+		}
+		defer func() {
+			_ = dataListener.Close()
+			dataListener = nil
+		}()
+		dataConn, err := dataListener.Accept()
+		if err != nil {
+			reply("425 no data connection")
+			return nil
+		}
+		reply("150 opening data connection")
+		return dataConn
+	}
+
+	user := ""
+	reply("220 fake ftp server")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		switch strings.ToUpper(command) {
+		case "USER":
+			user = arg
+			reply("331 password required")
+		case "PASS":
+			if user != s.user || arg != s.password {
+				reply("530 login incorrect")
+				continue
+			}
+			s.lock.Lock()
+			s.logins++
+			s.lock.Unlock()
+			reply("230 logged in")
+		case "TYPE":
+			reply("200 type set")
+		case "PWD":
+			reply("257 \"%s\"", cwd)
+		case "CWD":
+			dir := resolve(arg)
+			s.lock.Lock()
+			exists := s.dirs[dir]
+			s.lock.Unlock()
+			if !exists {
+				reply("550 no such directory")
+				continue
+			}
+			cwd = dir
+			reply("250 directory changed")
+		case "EPSV":
+			dataListener, err = net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				reply("421 failed to listen")
+				continue
+			}
+			reply("229 entering extended passive mode (|||%d|)", dataListener.Addr().(*net.TCPAddr).Port)
+		case "LIST":
+			if strings.HasPrefix(arg, "-") {
+				_, arg, _ = strings.Cut(arg, " ")
+			}
+			dir := resolve(arg)
+			dataConn := openData()
+			if dataConn == nil {
+				continue
+			}
+			s.lock.Lock()
+			for _, name := range s.children(dir) {
+				childPath := path.Join(dir, name)
+				if s.dirs[childPath] {
+					_, _ = fmt.Fprintf(dataConn, "drwxr-xr-x 1 owner group 0 Jan 01 00:00 %s\r\n", name)
+				} else {
+					_, _ = fmt.Fprintf(dataConn, "-rw-r--r-- 1 owner group %d Jan 01 00:00 %s\r\n", len(s.files[childPath]), name)
+				}
+			}
+			s.lock.Unlock()
+			_ = dataConn.Close()
+			reply("226 transfer complete")
+		case "RETR":
+			s.lock.Lock()
+			data, ok := s.files[resolve(arg)]
+			s.lock.Unlock()
+			if !ok {
+				reply("550 no such file")
+				continue
+			}
+			dataConn := openData()
+			if dataConn == nil {
+				continue
+			}
+			_, _ = dataConn.Write(data)
+			_ = dataConn.Close()
+			reply("226 transfer complete")
+		case "STOR":
+			dataConn := openData()
+			if dataConn == nil {
+				continue
+			}
+			data, _ := io.ReadAll(dataConn)
+			_ = dataConn.Close()
+			s.addFile(resolve(arg), data)
+			reply("226 transfer complete")
+		case "DELE":
+			s.lock.Lock()
+			_, ok := s.files[resolve(arg)]
+			delete(s.files, resolve(arg))
+			s.lock.Unlock()
+			if !ok {
+				reply("550 no such file")
+				continue
+			}
+			reply("250 deleted")
+		case "MKD":
+			s.lock.Lock()
+			s.dirs[resolve(arg)] = true
+			s.lock.Unlock()
+			reply("257 created")
+		case "QUIT":
+			s.lock.Lock()
+			s.quits++
+			s.lock.Unlock()
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// children returns the names of the entries in dir. s.lock must be held.
+func (s *fakeFTPServer) children(dir string) []string {
+	names := make([]string, 0)
+	for _, entries := range []map[string]bool{s.dirs, fileSet(s.files)} {
+		for entry := range entries {
+			if entry != "/" && path.Dir(entry) == dir {
+				names = append(names, path.Base(entry))
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func fileSet(files map[string][]byte) map[string]bool {
+	set := make(map[string]bool, len(files))
+	for file := range files {
+		set[file] = true
+	}
+	return set
+}
+
+func (s *fakeFTPServer) counts() (int, int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.logins, s.quits
+}
+
+func TestFTPDisk(t *testing.T) {
+	server := newFakeFTPServer(t, "user", "pass")
+	server.addFile("/server/FactoryServer.sh", []byte("#!/bin/sh"))
+
+	d, err := NewFTP(server.url(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exists, err := d.Exists("/server/FactoryServer.sh")
+	if err != nil || !exists {
+		t.Fatalf("Exists() = %v, %v, expected the file to exist", exists, err)
+	}
+	exists, err = d.Exists("/server/missing/FactoryServer.sh")
+	if err != nil || exists {
+		t.Fatalf("Exists() = %v, %v, expected the file to be missing", exists, err)
+	}
+
+	if err := d.MkDir("/server/FactoryGame/Mods"); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Write("/server/FactoryGame/Mods/test.txt", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := d.Read("/server/FactoryGame/Mods/test.txt")
+	if err != nil || string(data) != "data" {
+		t.Fatalf("Read() = %q, %v, expected the written data", data, err)
+	}
+
+	entries, err := d.ReadDir("/server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if expected := []string{"FactoryGame", "FactoryServer.sh"}; !slices.Equal(names, expected) {
+		t.Errorf("ReadDir() = %q, expected %q", names, expected)
+	}
+
+	if err := d.Remove("/server/FactoryGame/Mods/test.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The operations run one after the other, so they reuse a single connection, which is logged out of when closing
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The server handles the logout after Close returns
+	deadline := time.Now().Add(5 * time.Second)
+	logins, quits := server.counts()
+	for quits == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		logins, quits = server.counts()
+	}
+	if logins != 1 || quits != 1 {
+		t.Errorf("expected one connection to be made and closed, got %d logins and %d quits", logins, quits)
+	}
+
+	if _, err := d.Exists("/server/FactoryServer.sh"); err == nil {
+		t.Errorf("expected the closed disk to fail")
+	}
+}
+
+func TestFTPDiskWrongPassword(t *testing.T) {
+	server := newFakeFTPServer(t, "user", "pass")
+
+	u := server.url(false)
+	u.User = url.UserPassword("user", "wrong")
+	d, err := NewFTP(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if _, err := d.ReadDir("/"); err == nil {
+		t.Errorf("expected the login to fail")
+	}
+}
@@ -0,0 +1,286 @@
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// UnknownHostKeyError is returned when connecting to a server whose host key is not known yet.
+// The key can be pinned using HostKeyStore.Trust once the user confirms the fingerprint.
+type UnknownHostKeyError struct {
+	Host        string `json:"host"`
+	KeyType     string `json:"keyType"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+func (e *UnknownHostKeyError) Error() string {
+	return fmt.Sprintf("unknown host key for %s (%s %s)", e.Host, e.KeyType, e.Fingerprint)
+}
+
+// HostKeyMismatchError is returned when a server presents a different key than the one known for it
+type HostKeyMismatchError struct {
+	Host        string `json:"host"`
+	KeyType     string `json:"keyType"`
+	Fingerprint string `json:"fingerprint"`
+	KnownHosts  string `json:"knownHosts"`
+}
+
+func (e *HostKeyMismatchError) Error() string {
+	return fmt.Sprintf("host key for %s does not match the one in %s (got %s %s), the server may have been reinstalled, or the connection intercepted", e.Host, e.KnownHosts, e.KeyType, e.Fingerprint)
+}
+
+// HostKeyStore verifies host keys against the system known_hosts and the keys pinned by SMM.
+// Keys not found in either are rejected, and remembered until they are trusted,
+// unless the host was set to be trusted on first use, see TrustOnFirstUse.
+type HostKeyStore struct {
+	// PinnedPath is the known_hosts file SMM pins keys to.
+	// If empty, known_hosts in the SMM local directory is used.
+	PinnedPath string
+	// SystemPaths are additional known_hosts files checked before the pinned ones.
+	// If nil, the user's ~/.ssh/known_hosts is used.
+	SystemPaths []string
+
+	lock    sync.Mutex
+	pending map[string]ssh.PublicKey
+}
+
+var HostKeys = &HostKeyStore{}
+
+func (h *HostKeyStore) pinnedPath() string {
+	if h.PinnedPath != "" {
+		return h.PinnedPath
+	}
+	return filepath.Join(viper.GetString("smm-local-dir"), "known_hosts")
+}
+
+// firstUsePath is the file listing the hosts whose first key is pinned without asking
+func (h *HostKeyStore) firstUsePath() string {
+	return h.pinnedPath() + ".firstuse"
+}
+
+func (h *HostKeyStore) systemPaths() []string {
+	if h.SystemPaths != nil {
+		return h.SystemPaths
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{filepath.Join(homeDir, ".ssh", "known_hosts")}
+}
+
+// Callback is the ssh.HostKeyCallback that performs the verification
+func (h *HostKeyStore) Callback(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	for _, path := range append(slices.Clone(h.systemPaths()), h.pinnedPath()) {
+		known, err := checkKnownHosts(path, hostname, remote, key)
+		if err != nil {
+			return err
+		}
+		if known {
+			return nil
+		}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	firstUse, err := h.consumeFirstUseLocked(hostname)
+	if err != nil {
+		return err
+	}
+	if firstUse {
+		err := h.pinLocked(hostname, key)
+		if err != nil {
+			return err
+		}
+		slog.Warn("pinned host key of server added before host keys were checked", slog.String("host", hostname), slog.String("fingerprint", ssh.FingerprintSHA256(key)))
+		return nil
+	}
+
+	if h.pending == nil {
+		h.pending = make(map[string]ssh.PublicKey)
+	}
+	h.pending[hostname] = key
+
+	return &UnknownHostKeyError{
+		Host:        hostname,
+		KeyType:     key.Type(),
+		Fingerprint: ssh.FingerprintSHA256(key),
+	}
+}
+
+// checkKnownHosts returns whether the key is known for the host in the given known_hosts file
+func checkKnownHosts(path string, hostname string, remote net.Addr, key ssh.PublicKey) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		return false, nil
+	}
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	err = callback(hostname, remote, key)
+	if err == nil {
+		return true, nil
+	}
+	var keyErr *knownhosts.KeyError
+	if errors.As(err, &keyErr) {
+		if len(keyErr.Want) == 0 {
+			return false, nil
+		}
+		return false, &HostKeyMismatchError{
+			Host:        hostname,
+			KeyType:     key.Type(),
+			Fingerprint: ssh.FingerprintSHA256(key),
+			KnownHosts:  path,
+		}
+	}
+	return false, fmt.Errorf("failed to check host key: %w", err)
+}
+
+// Trust pins the key last presented by host, if its fingerprint matches the one the user confirmed
+func (h *HostKeyStore) Trust(host string, fingerprint string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	key, ok := h.pending[host]
+	if !ok {
+		return fmt.Errorf("no pending host key for %s", host)
+	}
+	if ssh.FingerprintSHA256(key) != fingerprint {
+		return fmt.Errorf("host key fingerprint for %s has changed", host)
+	}
+
+	err := h.pinLocked(host, key)
+	if err != nil {
+		return err
+	}
+
+	delete(h.pending, host)
+	return nil
+}
+
+func (h *HostKeyStore) pinLocked(host string, key ssh.PublicKey) error {
+	f, err := os.OpenFile(h.pinnedPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open known hosts: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(knownhosts.Line([]string{knownhosts.Normalize(host)}, key) + "\n")
+	if err != nil {
+		return fmt.Errorf("failed to write known hosts: %w", err)
+	}
+	return nil
+}
+
+// TrustOnFirstUse makes the first key each of the hosts presents be pinned without asking the user.
+// It is meant for SFTP servers added before SMM checked host keys, so they keep connecting after upgrading,
+// so it only has an effect the first time, before SMM pinned any key.
+// The hosts are kept until they connect, so servers that are offline at the time are not affected.
+func (h *HostKeyStore) TrustOnFirstUse(hosts []string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if _, err := os.Stat(h.pinnedPath()); err == nil {
+		return nil
+	}
+
+	if len(hosts) > 0 {
+		lines := make([]string, 0, len(hosts))
+		for _, host := range hosts {
+			lines = append(lines, knownhosts.Normalize(host)+"\n")
+		}
+		err := os.WriteFile(h.firstUsePath(), []byte(strings.Join(lines, "")), 0o600)
+		if err != nil {
+			return fmt.Errorf("failed to write first use hosts: %w", err)
+		}
+	}
+	// The pinned file existing marks the hosts as set up, even before any key is pinned
+	err := os.WriteFile(h.pinnedPath(), nil, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write known hosts: %w", err)
+	}
+	return nil
+}
+
+// consumeFirstUseLocked returns whether the host is trusted on first use, and removes it from the list if so
+func (h *HostKeyStore) consumeFirstUseLocked(host string) (bool, error) {
+	data, err := os.ReadFile(h.firstUsePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read first use hosts: %w", err)
+	}
+
+	normalized := knownhosts.Normalize(host)
+	found := false
+	kept := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		if line == normalized {
+			found = true
+			continue
+		}
+		kept = append(kept, line+"\n")
+	}
+	if !found {
+		return false, nil
+	}
+
+	if len(kept) == 0 {
+		err = os.Remove(h.firstUsePath())
+	} else {
+		err = os.WriteFile(h.firstUsePath(), []byte(strings.Join(kept, "")), 0o600)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write first use hosts: %w", err)
+	}
+	return true, nil
+}
+
+// Forget removes the keys SMM pinned for host, so that a changed key can be trusted again
+func (h *HostKeyStore) Forget(host string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	data, err := os.ReadFile(h.pinnedPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read known hosts: %w", err)
+	}
+
+	normalized := knownhosts.Normalize(host)
+	kept := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		hosts, _, _ := strings.Cut(line, " ")
+		if slices.Contains(strings.Split(hosts, ","), normalized) {
+			continue
+		}
+		kept = append(kept, line+"\n")
+	}
+
+	err = os.WriteFile(h.pinnedPath(), []byte(strings.Join(kept, "")), 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write known hosts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package remote
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func newHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func newTestHostKeyStore(t *testing.T) *HostKeyStore {
+	t.Helper()
+	return &HostKeyStore{
+		PinnedPath:  filepath.Join(t.TempDir(), "known_hosts"),
+		SystemPaths: []string{},
+	}
+}
+
+var testAddr = &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22}
+
+func TestHostKeyCallbackTrust(t *testing.T) {
+	store := newTestHostKeyStore(t)
+	key := newHostKey(t)
+
+	err := store.Callback("example.com:22", testAddr, key)
+	var unknownErr *UnknownHostKeyError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("expected UnknownHostKeyError, got %v", err)
+	}
+	if unknownErr.Fingerprint != ssh.FingerprintSHA256(key) {
+		t.Fatalf("unexpected fingerprint %s", unknownErr.Fingerprint)
+	}
+
+	if err := store.Trust("example.com:22", ssh.FingerprintSHA256(newHostKey(t))); err == nil {
+		t.Fatal("expected trusting a different fingerprint to fail")
+	}
+	if err := store.Trust(unknownErr.Host, unknownErr.Fingerprint); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Callback("example.com:22", testAddr, key); err != nil {
+		t.Fatalf("expected the trusted key to be accepted, got %v", err)
+	}
+
+	var mismatchErr *HostKeyMismatchError
+	if err := store.Callback("example.com:22", testAddr, newHostKey(t)); !errors.As(err, &mismatchErr) {
+		t.Fatalf("expected HostKeyMismatchError, got %v", err)
+	}
+
+	if err := store.Forget("example.com:22"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Callback("example.com:22", testAddr, key); !errors.As(err, &unknownErr) {
+		t.Fatalf("expected the forgotten key to be unknown, got %v", err)
+	}
+}
+
+func TestHostKeyCallbackSystemKnownHosts(t *testing.T) {
+	store := newTestHostKeyStore(t)
+	key := newHostKey(t)
+
+	systemPath := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(systemPath, []byte("[example.com]:2222 "+string(ssh.MarshalAuthorizedKey(key))), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store.SystemPaths = []string{systemPath}
+
+	if err := store.Callback("example.com:2222", testAddr, key); err != nil {
+		t.Fatalf("expected the system key to be accepted, got %v", err)
+	}
+	var unknownErr *UnknownHostKeyError
+	if err := store.Callback("example.com:22", testAddr, key); !errors.As(err, &unknownErr) {
+		t.Fatalf("expected the key to be unknown on another port, got %v", err)
+	}
+}
+
+func TestHostKeyCallbackTrustOnFirstUse(t *testing.T) {
+	store := newTestHostKeyStore(t)
+	key := newHostKey(t)
+
+	if err := store.TrustOnFirstUse([]string{"example.com", "other.example.com:2222"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Callback("example.com:22", testAddr, key); err != nil {
+		t.Fatalf("expected the first key to be pinned, got %v", err)
+	}
+	if err := store.Callback("example.com:22", testAddr, key); err != nil {
+		t.Fatalf("expected the pinned key to be accepted, got %v", err)
+	}
+	var mismatchErr *HostKeyMismatchError
+	if err := store.Callback("example.com:22", testAddr, newHostKey(t)); !errors.As(err, &mismatchErr) {
+		t.Fatalf("expected only the first key to be trusted, got %v", err)
+	}
+
+	var unknownErr *UnknownHostKeyError
+	if err := store.Callback("new.example.com:22", testAddr, key); !errors.As(err, &unknownErr) {
+		t.Fatalf("expected hosts not trusted on first use to be unknown, got %v", err)
+	}
+
+	// Already set up, so hosts added later are not trusted on first use
+	if err := store.TrustOnFirstUse([]string{"new.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Callback("new.example.com:22", testAddr, key); !errors.As(err, &unknownErr) {
+		t.Fatalf("expected hosts added later to be unknown, got %v", err)
+	}
+
+	if err := store.Callback("other.example.com:2222", testAddr, key); err != nil {
+		t.Fatalf("expected the remaining host to still be trusted on first use, got %v", err)
+	}
+	if _, err := os.Stat(store.firstUsePath()); !os.IsNotExist(err) {
+		t.Fatalf("expected the first use list to be removed once empty, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/satisfactorymodding/ficsit-cli/cli/disk"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
+)
+
+var _ disk.Disk = (*SFTPDisk)(nil)
+
+// SFTPDisk is a disk.Disk over SFTP that, unlike the ficsit-cli implementation,
+// supports key and agent authentication and verifies the server host key
+type SFTPDisk struct {
+	conn   *ssh.Client
+	client *sftp.Client
+}
+
+type sftpEntry struct {
+	os.FileInfo
+}
+
+func (e sftpEntry) IsDir() bool {
+	return e.FileInfo.IsDir()
+}
+
+func (e sftpEntry) Name() string {
+	return e.FileInfo.Name()
+}
+
+const defaultSFTPPort = "22"
+
+// NewSFTP connects to the server at the sftp:// URL u, authenticating with the credential
+func NewSFTP(u *url.URL, credential credentials.Credential, hostKeyCallback ssh.HostKeyCallback) (*SFTPDisk, error) {
+	auth, err := NewAuth(credential)
+	if err != nil {
+		return nil, err
+	}
+	// The agent is only needed during the handshake
+	defer auth.Close()
+
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), defaultSFTPPort)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            u.User.Username(),
+		Auth:            auth.Methods,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         10 * time.Second,
+	}
+
+	conn, err := net.DialTimeout("tcp", host, config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", u.Hostname(), err)
+	}
+
+	return NewSFTPFromConn(conn, host, config)
+}
+
+// NewSFTPFromConn sets up an SFTP session over an existing connection.
+// This allows using any transport, such as an in-memory pipe to a local SFTP server stand-in.
+func NewSFTPFromConn(conn net.Conn, addr string, config *ssh.ClientConfig) (*SFTPDisk, error) {
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to connect to ssh server: %w", err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+
+	client, err := sftp.NewClient(sshClient)
+	if err != nil {
+		_ = sshClient.Close()
+		return nil, fmt.Errorf("failed to create sftp client: %w", err)
+	}
+
+	slog.Info("logged into sftp")
+
+	return &SFTPDisk{
+		conn:   sshClient,
+		client: client,
+	}, nil
+}
+
+func (d *SFTPDisk) Close() error {
+	clientErr := d.client.Close()
+	connErr := d.conn.Close()
+	if clientErr != nil {
+		return fmt.Errorf("failed to close sftp client: %w", clientErr)
+	}
+	if connErr != nil && !errors.Is(connErr, net.ErrClosed) {
+		return fmt.Errorf("failed to close ssh connection: %w", connErr)
+	}
+	return nil
+}
+
+// clean returns a unix-style path
+func clean(path string) string {
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func (d *SFTPDisk) Exists(path string) (bool, error) {
+	_, err := d.client.Stat(clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check if file exists: %w", err)
+	}
+	return true, nil
+}
+
+func (d *SFTPDisk) Read(path string) ([]byte, error) {
+	f, err := d.client.Open(clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return data, nil
+}
+
+func (d *SFTPDisk) Write(path string, data []byte) error {
+	f, err := d.client.Create(clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+func (d *SFTPDisk) Remove(path string) error {
+	if err := d.client.Remove(clean(path)); err != nil {
+		if err := d.client.RemoveAll(clean(path)); err != nil {
+			return fmt.Errorf("failed to delete path: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *SFTPDisk) MkDir(path string) error {
+	if err := d.client.MkdirAll(clean(path)); err != nil {
+		return fmt.Errorf("failed to make directory: %w", err)
+	}
+	return nil
+}
+
+func (d *SFTPDisk) ReadDir(path string) ([]disk.Entry, error) {
+	dir, err := d.client.ReadDir(clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in directory: %w", err)
+	}
+
+	entries := make([]disk.Entry, len(dir))
+	for i, entry := range dir {
+		entries[i] = sftpEntry{FileInfo: entry}
+	}
+	return entries, nil
+}
+
+func (d *SFTPDisk) Open(path string, _ int) (io.WriteCloser, error) {
+	f, err := d.client.Create(clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return f, nil
+}
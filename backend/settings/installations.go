@@ -0,0 +1,173 @@
+package settings
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// InstallationSettings are the settings SMM keeps for a single installation,
+// separately from the installations.json shared with ficsit-cli
+type InstallationSettings struct {
+	// Label is the name shown instead of the path. If empty, the launcher name is shown.
+	Label string `json:"label,omitempty"`
+	// Color is a #rrggbb color used to tell installations apart
+	Color string `json:"color,omitempty"`
+	// Order is the position of the installation in lists, starting at 1. Installations without one are listed last.
+	Order int `json:"order,omitempty"`
+	// Hidden installations are not listed, but can still be selected and modded
+	Hidden bool `json:"hidden,omitempty"`
+	// ModOverrides change how the profile of the installation applies to it, by mod reference
+	ModOverrides map[string]ModOverride `json:"modOverrides,omitempty"`
+	// LastSeenGameVersion is the changelist of the game when SMM last found the installation, used to notice game updates
+	LastSeenGameVersion int `json:"lastSeenGameVersion,omitempty"`
+	// LaunchOptions change how the game is launched. If nil, the launch command of the launcher is used as is.
+	LaunchOptions *LaunchOptions `json:"launchOptions,omitempty"`
+}
+
+// ModOverride changes a single mod of the profile, only for one installation
+type ModOverride struct {
+	// Enabled forces the mod to be enabled or disabled. If nil, the profile decides.
+	Enabled *bool `json:"enabled,omitempty"`
+	// Version pins the mod to a different version constraint than the profile. If empty, the profile decides.
+	Version string `json:"version,omitempty"`
+}
+
+func (o ModOverride) IsEmpty() bool {
+	return o.Enabled == nil && o.Version == ""
+}
+
+// LaunchOptions change the launch command of an installation.
+// Launchers that start the game themselves, such as Steam or the Epic Games Launcher, only support some of them.
+type LaunchOptions struct {
+	// Args are added to the end of the launch command
+	Args []string `json:"args,omitempty"`
+	// Env are environment variables set for the launch command
+	Env map[string]string `json:"env,omitempty"`
+	// Wrapper is a command the launch command is run with, such as gamemoderun, mangohud, or a custom script
+	Wrapper []string `json:"wrapper,omitempty"`
+}
+
+func (o LaunchOptions) IsEmpty() bool {
+	return len(o.Args) == 0 && len(o.Env) == 0 && len(o.Wrapper) == 0
+}
+
+func (o LaunchOptions) clone() LaunchOptions {
+	o.Args = slices.Clone(o.Args)
+	o.Env = maps.Clone(o.Env)
+	o.Wrapper = slices.Clone(o.Wrapper)
+	return o
+}
+
+// ManualInstallation is a local installation added by the user, because no launcher finds it
+type ManualInstallation struct {
+	Path string `json:"path"`
+	// LaunchCommand is used instead of running the game executable, if set
+	LaunchCommand []string `json:"launchCommand,omitempty"`
+}
+
+// installationsLock guards Settings.Installations and Settings.ManualInstallations, which is accessed while actions run in parallel
+var installationsLock sync.RWMutex
+
+func (s InstallationSettings) isEmpty() bool {
+	return s.Label == "" && s.Color == "" && s.Order == 0 && !s.Hidden && len(s.ModOverrides) == 0 && s.LastSeenGameVersion == 0 && s.LaunchOptions == nil
+}
+
+func (s InstallationSettings) clone() InstallationSettings {
+	s.ModOverrides = maps.Clone(s.ModOverrides)
+	if s.LaunchOptions != nil {
+		launchOptions := s.LaunchOptions.clone()
+		s.LaunchOptions = &launchOptions
+	}
+	return s
+}
+
+// GetInstallationSettings returns a copy of the settings of the installation at path
+func GetInstallationSettings(path string) InstallationSettings {
+	installationsLock.RLock()
+	defer installationsLock.RUnlock()
+	return Settings.Installations[path].clone()
+}
+
+// UpdateInstallationSettings applies update to the settings of the installation at path, then saves the settings
+func UpdateInstallationSettings(path string, update func(*InstallationSettings)) error {
+	installationsLock.Lock()
+	installationSettings := Settings.Installations[path].clone()
+	update(&installationSettings)
+	if Settings.Installations == nil {
+		Settings.Installations = make(map[string]InstallationSettings)
+	}
+	if installationSettings.isEmpty() {
+		delete(Settings.Installations, path)
+	} else {
+		Settings.Installations[path] = installationSettings
+	}
+	installationsLock.Unlock()
+
+	return SaveSettings()
+}
+
+// MoveInstallationSettings keeps the settings of an installation whose path changed
+func MoveInstallationSettings(oldPath string, newPath string) error {
+	installationsLock.Lock()
+	installationSettings, ok := Settings.Installations[oldPath]
+	if ok {
+		delete(Settings.Installations, oldPath)
+		Settings.Installations[newPath] = installationSettings
+	}
+	installationsLock.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return SaveSettings()
+}
+
+func DeleteInstallationSettings(path string) error {
+	installationsLock.Lock()
+	_, ok := Settings.Installations[path]
+	delete(Settings.Installations, path)
+	installationsLock.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return SaveSettings()
+}
+
+func GetManualInstallations() []ManualInstallation {
+	installationsLock.RLock()
+	defer installationsLock.RUnlock()
+	return slices.Clone(Settings.ManualInstallations)
+}
+
+// SetManualInstallation adds the manual installation, or replaces the one with the same path, then saves the settings
+func SetManualInstallation(installation ManualInstallation) error {
+	installationsLock.Lock()
+	idx := slices.IndexFunc(Settings.ManualInstallations, func(i ManualInstallation) bool {
+		return i.Path == installation.Path
+	})
+	if idx == -1 {
+		Settings.ManualInstallations = append(Settings.ManualInstallations, installation)
+	} else {
+		Settings.ManualInstallations[idx] = installation
+	}
+	installationsLock.Unlock()
+
+	return SaveSettings()
+}
+
+func DeleteManualInstallation(path string) error {
+	installationsLock.Lock()
+	before := len(Settings.ManualInstallations)
+	Settings.ManualInstallations = slices.DeleteFunc(Settings.ManualInstallations, func(i ManualInstallation) bool {
+		return i.Path == path
+	})
+	deleted := len(Settings.ManualInstallations) != before
+	installationsLock.Unlock()
+
+	if !deleted {
+		return nil
+	}
+	return SaveSettings()
+}
@@ -51,6 +51,9 @@ type settings struct {
 	UpdateCheckMode     UpdateCheckMode     `json:"updateCheckMode,omitempty"`
 	ViewedAnnouncements []string            `json:"viewedAnnouncements,omitempty"`
 
+	// QueueModChangesWhileRunning makes mod changes to an installation whose game is running wait until it exits, instead of failing
+	QueueModChangesWhileRunning bool `json:"queueModChangesWhileRunning,omitempty"`
+
 	Offline bool `json:"offline,omitempty"`
 
 	Proxy string `json:"proxy,omitempty"`
@@ -61,6 +64,9 @@ type settings struct {
 	CacheDir string `json:"cacheDir,omitempty"`
 
 	Debug bool `json:"debug,omitempty"`
+
+	Installations       map[string]InstallationSettings `json:"installations,omitempty"`
+	ManualInstallations []ManualInstallation            `json:"manualInstallations,omitempty"`
 }
 
 var Settings = &settings{
@@ -89,6 +95,8 @@ var Settings = &settings{
 	LaunchButton: "normal",
 
 	Debug: false,
+
+	Installations: map[string]InstallationSettings{},
 }
 
 func (s *settings) FavoriteMod(modReference string) (bool, error) {
@@ -190,6 +198,15 @@ func (s *settings) SetQueueAutoStart(value bool) {
 	_ = SaveSettings()
 }
 
+func (s *settings) GetQueueModChangesWhileRunning() bool {
+	return s.QueueModChangesWhileRunning
+}
+
+func (s *settings) SetQueueModChangesWhileRunning(value bool) {
+	s.QueueModChangesWhileRunning = value
+	_ = SaveSettings()
+}
+
 func (s *settings) GetIgnoredUpdates() map[string][]string {
 	return s.IgnoredUpdates
 }
@@ -395,7 +412,9 @@ func LoadSettings() error {
 }
 
 func SaveSettings() error {
+	installationsLock.RLock()
 	settingsFile, err := utils.JSONMarshal(Settings, 2)
+	installationsLock.RUnlock()
 	if err != nil {
 		return fmt.Errorf("failed to marshal settings: %w", err)
 	}
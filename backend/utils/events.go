@@ -10,10 +10,14 @@ type EventDispatcher[D any] struct {
 
 func (ed *EventDispatcher[D]) On(f func(D)) func() {
 	ed.listeners = append(ed.listeners, &f)
+	listener := eventListener[D](&f)
 	return func() {
-		slices.DeleteFunc(ed.listeners, func(listener eventListener[D]) bool {
-			return listener == &f
-		})
+		// Only mark the listener as removed, since this might be called during Dispatch
+		for i := range ed.listeners {
+			if ed.listeners[i] == listener {
+				ed.listeners[i] = nil
+			}
+		}
 	}
 }
 
@@ -32,7 +36,7 @@ func (ed *EventDispatcher[D]) Dispatch(data D) {
 		}
 		(*listener)(data)
 	}
-	slices.DeleteFunc(ed.listeners, func(listener eventListener[D]) bool {
+	ed.listeners = slices.DeleteFunc(ed.listeners, func(listener eventListener[D]) bool {
 		return listener == nil
 	})
 }
@@ -1,13 +1,13 @@
 import { derived, get, writable } from 'svelte/store';
 
 import { isLaunchingGame } from './generalStore';
-import { ignoredUpdates } from './settingsStore';
+import { ignoredUpdates, queueModChangesWhileRunning } from './settingsStore';
 import { binding, bindingTwoWay } from './wailsStoreBindings';
 
 import { bytesToAppropriate, secondsToAppropriate } from '$lib/utils/dataFormats';
 import { timeSeries } from '$lib/utils/timeSeries';
-import { CheckForUpdates, GetInstallations, GetInstallationsMetadata, GetInvalidInstalls, GetModsEnabled, GetProfiles, GetRemoteInstallations, GetSelectedInstall, GetSelectedInstallLockfileMods, GetSelectedInstallProfileMods, GetSelectedProfile, SelectInstall, SetModsEnabled, SetProfile } from '$wailsjs/go/ficsitcli/ficsitCLI';
-import { type cli, common, ficsitcli } from '$wailsjs/go/models';
+import { CheckForUpdates, GetBlockedInstallations, GetGameVersionChanges, GetInstallations, GetInstallationsMetadata, GetInvalidInstalls, GetModsEnabled, GetProfiles, GetQueuedActions, GetRemoteInstallations, GetRunningProcesses, GetSelectedInstall, GetSelectedInstallLockfileMods, GetSelectedInstallProfileMods, GetSelectedProfile, SelectInstall, SetModsEnabled, SetProfile } from '$wailsjs/go/ficsitcli/ficsitCLI';
+import { type cli, common, ficsitcli, type gameprocess } from '$wailsjs/go/models';
 import { GetFavoriteMods } from '$wailsjs/go/settings/settings';
 
 export const invalidInstalls = binding([], { initialGet: GetInvalidInstalls });
@@ -45,16 +45,33 @@ export const favoriteMods = binding<string[]>([], { updateEvent: 'favoriteMods',
 
 export const isGameRunning = binding(false, { updateEvent: 'isGameRunning', allowNull: false });
 
-export const canModify = derived([isGameRunning, progress, isLaunchingGame, installs, selectedInstallMetadata], ([$isGameRunning, $progress, $isLaunchingGame, $installs, $selectedInstallMetadata]) => {
-  return !$isGameRunning && !$progress && !$isLaunchingGame && $installs.length > 0 && $selectedInstallMetadata?.state === ficsitcli.InstallState.VALID;
+export const runningProcesses = binding<gameprocess.Process[]>([], { initialGet: GetRunningProcesses, updateEvent: 'runningProcesses', allowNull: false });
+
+export const blockedInstallations = binding<string[]>([], { initialGet: GetBlockedInstallations, updateEvent: 'blockedInstallations', allowNull: false });
+
+export const queuedActions = binding<ficsitcli.Progress[]>([], { initialGet: GetQueuedActions, updateEvent: 'queuedActions', allowNull: false });
+
+// A running game doesn't stop mod changes if they wait for it to exit, or if the user chose to change the mods anyway.
+// A game that could not be matched to an installation might be the selected one, so it always stops them.
+export const modChangesBlocked = derived([isGameRunning, queueModChangesWhileRunning, blockedInstallations, runningProcesses, selectedInstall], ([$isGameRunning, $queueModChangesWhileRunning, $blockedInstallations, $runningProcesses, $selectedInstall]) => {
+  if (!$isGameRunning || $queueModChangesWhileRunning) {
+    return false;
+  }
+  return $selectedInstall === null || $blockedInstallations.includes($selectedInstall) || !$runningProcesses.some((p) => p.installation === $selectedInstall);
+});
+
+export const gameVersionChanges = binding<ficsitcli.GameVersionChange[]>([], { initialGet: GetGameVersionChanges, updateEvent: 'gameVersionChanges', allowNull: false });
+
+export const canModify = derived([modChangesBlocked, progress, isLaunchingGame, installs, selectedInstallMetadata], ([$modChangesBlocked, $progress, $isLaunchingGame, $installs, $selectedInstallMetadata]) => {
+  return !$modChangesBlocked && !$progress && !$isLaunchingGame && $installs.length > 0 && $selectedInstallMetadata?.state === ficsitcli.InstallState.VALID;
 });
 
 export const canChangeInstall = derived([isGameRunning, progress, isLaunchingGame, installs], ([$isGameRunning, $progress, $isLaunchingGame, $installs]) => {
   return !$isGameRunning && !$progress && !$isLaunchingGame && $installs.length > 0;
 });
 
-export const canInstallMods = derived([isGameRunning, isLaunchingGame, installs, selectedInstallMetadata], ([$isGameRunning, $isLaunchingGame, $installs, $selectedInstallMetadata]) => {
-  return !$isGameRunning && !$isLaunchingGame && $installs.length > 0 && $selectedInstallMetadata?.state === ficsitcli.InstallState.VALID;
+export const canInstallMods = derived([modChangesBlocked, isLaunchingGame, installs, selectedInstallMetadata], ([$modChangesBlocked, $isLaunchingGame, $installs, $selectedInstallMetadata]) => {
+  return !$modChangesBlocked && !$isLaunchingGame && $installs.length > 0 && $selectedInstallMetadata?.state === ficsitcli.InstallState.VALID;
 });
 
 export const updates = writable<ficsitcli.Update[]>([]);
@@ -2,5 +2,8 @@ import { writable } from 'svelte/store';
 
 export const expandedMod = writable(null as string | null);
 export const error = writable<string|null>(null);
+// The error of actions that were queued until the game exits, ErrActionQueued in the backend.
+// It is not shown as an error, since the queued changes are shown next to the running game, and their failures are reported separately.
+export const actionQueuedError = 'the game is running, the change will be made once it exits';
 export const isLaunchingGame = writable(false);
 export const siteURL = writable<string>('https://ficsit.app/');
@@ -49,7 +49,8 @@ export async function getReportedCompatibility(modReference: string, gameBranch:
       case common.GameBranch.EXPERIMENTAL:
         return mod.compatibility.EXP;
       default:
-        throw new Error('Invalid game branch');
+        // Unknown branches have no reported compatibility, so only the version check applies
+        return undefined;
     }
   }
   return undefined;
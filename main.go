@@ -20,9 +20,12 @@ import (
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/app"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/autoupdate"
 	appCommon "github.com/satisfactorymodding/SatisfactoryModManager/backend/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/credentials"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/ficsitcli"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/common"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/installfinders/launchers/custom"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/logging"
+	"github.com/satisfactorymodding/SatisfactoryModManager/backend/remote"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/settings"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/utils"
 	"github.com/satisfactorymodding/SatisfactoryModManager/backend/wailsextras"
@@ -80,6 +83,17 @@ func main() {
 		}
 	}
 
+	err = credentials.LoadCredentials()
+	if err != nil {
+		// The unreadable credentials were reset, so SMM can still start, and the user has to enter them again
+		slog.Error("failed to load credentials", slog.Any("error", err))
+		_ = dialog.Warning("The saved remote server credentials could not be read, so they were reset. You will need to enter the passwords of your remote servers again.\n\n%s", err.Error())
+	}
+
+	for _, err := range custom.LoadDefinitions(filepath.Join(viper.GetString("smm-local-dir"), "launchers")) {
+		slog.Error("failed to load custom launcher", slog.Any("error", err))
+	}
+
 	err = ficsitcli.Init()
 	if err != nil {
 		slog.Error("failed to initialize ficsit-cli", slog.Any("error", err))
@@ -133,7 +147,8 @@ func main() {
 			app.App.WatchWindow() //nolint:contextcheck
 			go websocket.ListenAndServeWebsocket()
 
-			ficsitcli.FicsitCLI.StartGameRunningWatcher() //nolint:contextcheck
+			ficsitcli.FicsitCLI.StartGameRunningWatcher()   //nolint:contextcheck
+			ficsitcli.FicsitCLI.StartInstallationsWatcher() //nolint:contextcheck
 		},
 		OnDomReady: func(ctx context.Context) {
 			backend.ProcessArguments(os.Args[1:]) //nolint:contextcheck
@@ -141,6 +156,8 @@ func main() {
 		},
 		OnShutdown: func(ctx context.Context) {
 			app.App.StopWindowWatcher()
+			ficsitcli.ServerPicker.StopAllPickers()
+			ficsitcli.FicsitCLI.StopInstallationsWatcher()
 		},
 		Bind: []interface{}{
 			app.App,
@@ -155,6 +172,9 @@ func main() {
 			common.AllLocationTypes,
 			ficsitcli.AllInstallationStates,
 			ficsitcli.AllActionTypes,
+			ficsitcli.AllLaunchErrorReasons,
+			remote.AllDiagnosticStages,
+			remote.AllDiagnosticStageStatuses,
 		},
 		Logger: backend.WailsZeroLogLogger{},
 	})